@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/config"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const pluginConfigDesc = `管理 s2i 的設定檔
+
+s2i 會依序讀取以下設定檔, 後讀取的會覆蓋先前的值:
+
+	- user 層級: $HOME/.s2i.yaml
+	- repo 層級: 當前目錄的 .s2i.yaml
+
+設定檔中最外層的 key 為所有 command 共用, 'prerelease', 'release', 'promote', 'rollback' 及 'service' 下的 key 則只作用在該 command, 如:
+
+	github-url: https://github.example.com
+	deployer: http://softleader.com.tw:5678
+	prerelease:
+	  config-label: sqlServer
+	  service-id: SERVICE_ID
+	release:
+	  jenkins: https://jenkins.softleader.com.tw
+
+'registry' 及 'image-namespace' 可以調整 image 要 push 到的 registry 及 namespace, 預設為 hub.softleader.com.tw 且沒有 namespace, 如:
+
+	registry: registry.example.com
+	image-namespace: team
+
+'deployer-token' 及 'deployer-timeout' 可以設定呼叫 deployer 時的 bearer token 及 timeout, 建議寫在 user 層級或以 '$S2I_DEPLOYER_TOKEN' 傳入, 避免 token 被 commit 到 repo 中
+
+'stages' 可以調整 pre-release 各開發階段對應到 tag 上的 identifier, 預設為:
+
+	stages:
+	  alpha: "0"
+	  beta: "1"
+	  rc: "2"
+
+可以寫在設定檔中的 flag 也都可以透過 'S2I_' 開頭的環境變數設定, 如 '--config-label' 對應 '$S2I_CONFIG_LABEL',
+但 '--yes', '--force', '--overwrite-image' 及 '--dry-run' 等略過確認或保護機制的 flag 只能在 command line 傳入
+最終的優先順序為: flag > 環境變數 > repo 層級 > user 層級 > 預設值
+`
+
+const (
+	envPrefix = "S2I_"
+)
+
+// unsafeEnvFlags 是略過確認或保護機制的 flag, 只能在 command line 明確傳入, 不會被環境變數設定
+var unsafeEnvFlags = map[string]bool{
+	"yes":             true,
+	"force":           true,
+	"overwrite-image": true,
+	"dry-run":         true,
+}
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "manage s2i config files",
+		Long:  pluginConfigDesc,
+	}
+	cmd.AddCommand(
+		newConfigViewCmd(),
+	)
+	return cmd
+}
+
+// globalConfig 讓 global flags 也可以寫在設定檔的最外層, 欄位使用 pointer 以直接寫回 global 變數
+type globalConfig struct {
+	Remote          *string        `yaml:"remote"`
+	GitHubURL       *string        `yaml:"github-url"`
+	SCM             *string        `yaml:"scm"`
+	SCMURL          *string        `yaml:"scm-url"`
+	Registry        *string        `yaml:"registry"`
+	Namespace       *string        `yaml:"image-namespace"`
+	DeployerToken   *string        `yaml:"deployer-token"`
+	DeployerTimeout *time.Duration `yaml:"deployer-timeout"`
+	Stages          *scm.Stages    `yaml:"stages"`
+}
+
+// loadGlobalConfig 依照 flag > env > repo 設定檔 > user 設定檔 > 預設值 的優先順序合併 global flags
+func loadGlobalConfig(f *pflag.FlagSet) error {
+	pwd, _ := os.Getwd()
+	return mergeConfig(f, changedFlags(f), pwd, "", &globalConfig{
+		Remote:          &remote,
+		GitHubURL:       &githubURL,
+		SCM:             &scmProvider,
+		SCMURL:          &scmURL,
+		Registry:        &imageRegistry,
+		Namespace:       &imageNamespace,
+		DeployerToken:   &deployerToken,
+		DeployerTimeout: &deployerTimeout,
+		Stages:          stages,
+	})
+}
+
+// changedFlags 回傳使用者有明確傳入的 flags 及其值
+func changedFlags(f *pflag.FlagSet) map[string]string {
+	changed := make(map[string]string)
+	f.Visit(func(flag *pflag.Flag) {
+		changed[flag.Name] = flag.Value.String()
+	})
+	return changed
+}
+
+// mergeConfig 依照 flag > env > repo 設定檔 > user 設定檔 > 預設值 的優先順序合併到 c 中
+func mergeConfig(f *pflag.FlagSet, changed map[string]string, pwd, section string, c interface{}) (err error) {
+	if err = config.Load(logrus.StandardLogger(), pwd, section, c); err != nil {
+		return fmt.Errorf("failed to load config: %s", err)
+	}
+	keys := configKeys(reflect.TypeOf(c))
+	f.VisitAll(func(flag *pflag.Flag) {
+		if _, found := changed[flag.Name]; found || err != nil || !keys[flag.Name] || unsafeEnvFlags[flag.Name] {
+			return
+		}
+		if v, found := os.LookupEnv(envName(flag.Name)); found {
+			logrus.Debugf("found $%s, overrides '--%s' to %q", envName(flag.Name), flag.Name, v)
+			if err = flag.Value.Set(v); err != nil {
+				err = fmt.Errorf("invalid value %q of $%s: %s", v, envName(flag.Name), err)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for name, v := range changed {
+		if err = f.Set(name, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configKeys 回傳 t 在設定檔中對應的 key, 包含 inline 的欄位; 只有與 key 同名的 flag 才能以環境變數設定
+func configKeys(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	keys := make(map[string]bool)
+	if t.Kind() != reflect.Struct {
+		return keys
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		switch name := tag[0]; {
+		case name == "-":
+		case len(tag) > 1 && tag[1] == "inline":
+			for key := range configKeys(field.Type) {
+				keys[key] = true
+			}
+		case field.PkgPath != "": // 沒有 export 的欄位不會被 yaml 讀取
+		case name == "":
+			keys[strings.ToLower(field.Name)] = true
+		default:
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// envName 回傳 flag 對應的環境變數名稱, e.g. config-server -> S2I_CONFIG_SERVER
+func envName(flag string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
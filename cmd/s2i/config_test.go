@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/pflag"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMergeConfig_Env(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(home string, cache bool) {
+		os.Setenv("HOME", home)
+		homedir.DisableCache = cache
+	}(os.Getenv("HOME"), homedir.DisableCache)
+	os.Setenv("HOME", dir)
+	homedir.DisableCache = true
+
+	for name, v := range map[string]string{"S2I_CONFIG_LABEL": "sqlServer", "S2I_FORCE": "true", "S2I_YES": "true", "S2I_SHIP_STRATEGY": "2"} {
+		os.Setenv(name, v)
+		defer os.Unsetenv(name)
+	}
+	c := &struct {
+		ConfigLabel string `yaml:"config-label"`
+		Force       bool   `yaml:"-"`
+		Yes         bool
+		strategy    int
+	}{}
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.StringVar(&c.ConfigLabel, "config-label", "", "")
+	f.BoolVar(&c.Force, "force", false, "")
+	f.BoolVar(&c.Yes, "yes", false, "")
+	f.IntVar(&c.strategy, "ship-strategy", 0, "")
+	if err := mergeConfig(f, nil, dir, "test", c); err != nil {
+		t.Fatal(err)
+	}
+	if c.ConfigLabel != "sqlServer" {
+		t.Errorf("$S2I_CONFIG_LABEL should be applied, but got %q", c.ConfigLabel)
+	}
+	if c.Force || c.Yes {
+		t.Errorf("$S2I_FORCE and $S2I_YES should be ignored, but got force %v, yes %v", c.Force, c.Yes)
+	}
+	if c.strategy != 0 {
+		t.Errorf("flags without config key should not be set by env, but got %d", c.strategy)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/docker"
+	"github.com/softleader/s2i/pkg/jib"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+const pluginConfigViewDesc = `印出合併設定檔, 環境變數及預設值後, 最終生效的設定
+
+	$ s2i config view
+`
+
+type configViewCmd struct {
+	Remote     string         `yaml:"remote"`
+	GitHubURL  string         `yaml:"github-url"`
+	SCM        string         `yaml:"scm"`
+	SCMURL     string         `yaml:"scm-url"`
+	Registry   string         `yaml:"registry"`
+	Namespace  string         `yaml:"image-namespace"`
+	Stages     *scm.Stages    `yaml:"stages"`
+	Prerelease *prereleaseCmd `yaml:"prerelease"`
+	Release    *releaseCmd    `yaml:"release"`
+	Promote    *promoteCmd    `yaml:"promote"`
+	Rollback   *rollbackCmd   `yaml:"rollback"`
+}
+
+func newConfigViewCmd() *cobra.Command {
+	c := &configViewCmd{
+		Prerelease: &prereleaseCmd{
+			Auth:  &jib.Auth{},
+			Image: &docker.Image{},
+		},
+		Release: &releaseCmd{
+			Auth:  &jib.Auth{},
+			Image: &docker.Image{},
+		},
+		Promote: &promoteCmd{
+			Auth:  &jib.Auth{},
+			Image: &docker.Image{},
+		},
+		Rollback: &rollbackCmd{},
+	}
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "print the effective merged config",
+		Long:  pluginConfigViewDesc,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run()
+		},
+	}
+	return cmd
+}
+
+func (c *configViewCmd) run() error {
+	pf := pflag.NewFlagSet("prerelease", pflag.ContinueOnError)
+	c.Prerelease.flags(pf)
+	if err := c.Prerelease.loadConfig(pf); err != nil {
+		return err
+	}
+	rf := pflag.NewFlagSet("release", pflag.ContinueOnError)
+	c.Release.flags(rf)
+	if err := c.Release.loadConfig(rf); err != nil {
+		return err
+	}
+	mf := pflag.NewFlagSet("promote", pflag.ContinueOnError)
+	c.Promote.flags(mf)
+	if err := c.Promote.loadConfig(mf); err != nil {
+		return err
+	}
+	bf := pflag.NewFlagSet("rollback", pflag.ContinueOnError)
+	c.Rollback.flags(bf)
+	if err := c.Rollback.loadConfig(bf); err != nil {
+		return err
+	}
+	c.Remote, c.GitHubURL = remote, githubURL
+	c.SCM, c.SCMURL, _ = resolveSCM()
+	c.Registry, c.Namespace = imageRegistry, imageNamespace
+	c.Stages = stages
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	logrus.Println(string(b))
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/deployer"
+	"github.com/softleader/s2i/pkg/docker"
+	"github.com/spf13/pflag"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rolloutInterval 是等待 rollout 時查詢 deployer 的間隔
+const rolloutInterval = 3 * time.Second
+
+// newDeployer 產生呼叫 url 的 deployer client, 套用 global 的 token 及 timeout
+func newDeployer(url string) *deployer.Client {
+	return deployer.NewClient(url).
+		SetLogger(logrus.StandardLogger()).
+		SetVerbose(verbose).
+		SetUserAgent("s2i", metadata.String()).
+		SetTimeout(deployerTimeout).
+		SetAuthToken(deployerToken)
+}
+
+// services 是要更新的 docker swarm services, 可以直接指定 id 或以 label 篩選
+type services struct {
+	ServiceIDs    stringList `yaml:"service-id"`
+	ServiceLabels stringList `yaml:"service-label"`
+	Parallel      int
+}
+
+func (s *services) flags(f *pflag.FlagSet) {
+	stringListVar(f, &s.ServiceIDs, "service-id", "docker swarm service id to update (repeatable)")
+	stringListVar(f, &s.ServiceLabels, "service-label", "update docker swarm services with the label, e.g. app=foo (repeatable, any of them)")
+	f.IntVar(&s.Parallel, "parallel", 1, "number of services to update in parallel")
+}
+
+// isEmpty 回傳是否沒有指定任何要更新的 service
+func (s *services) isEmpty() bool {
+	return len(s.ServiceIDs) == 0 && len(s.ServiceLabels) == 0
+}
+
+// resolve 回傳所有要更新的 service id, 包含直接指定的及符合任一 label 的, 重複的 id 只會出現一次
+func (s *services) resolve(d *deployer.Client) (ids []string, err error) {
+	found := make(map[string]bool)
+	add := func(id string) {
+		if !found[id] {
+			found[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range s.ServiceIDs {
+		add(id)
+	}
+	for _, label := range s.ServiceLabels {
+		filtered, err := d.FilterService(map[string]string{"label": label})
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter services by label %s: %s", label, err)
+		}
+		if len(filtered) == 0 {
+			logrus.Warnf("no services found with label %s", label)
+		}
+		for _, service := range filtered {
+			logrus.Debugf("found service %s (%s) with label %s", service.Name, service.ID, label)
+			add(service.ID)
+		}
+	}
+	return ids, nil
+}
+
+// updateServices 以 parallel 個 worker 將所有 services 更新為 image, 並印出每個 service 的結果, 任一個失敗時回傳 error
+func updateServices(url string, s services, image *docker.Image, skipSlack bool, r rollout) error {
+	ids, err := s.resolve(newDeployer(url))
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("no services to update")
+	}
+	parallel := s.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	results := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				err := updateService(url, id, image, skipSlack, r)
+				mu.Lock()
+				results[id] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, id := range ids {
+		queue <- id
+	}
+	close(queue)
+	wg.Wait()
+
+	if len(ids) == 1 {
+		return results[ids[0]]
+	}
+	var failed []string
+	logrus.Printf("Results of updating %d service(s) to %s:", len(ids), image)
+	for _, id := range ids {
+		if err := results[id]; err != nil {
+			logrus.Errorf("  %s: %s", id, err)
+			failed = append(failed, id)
+		} else {
+			logrus.Printf("  %s: ok", id)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to update %d of %d service(s): %s", len(failed), len(ids), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// rollout 是更新 service 後是否等待所有 tasks 都以新 image 執行, 及失敗時是否回復到更新前的 image
+type rollout struct {
+	Wait        bool
+	WaitTimeout time.Duration `yaml:"wait-timeout"`
+	Rollback    bool          `yaml:"rollback-on-failure"` // 不能使用 rollback, 會與設定檔中 'rollback' command 的 section 衝突
+}
+
+// flags 綁定 rollout 的 flags, rollback 為 false 時不提供 '--rollback-on-failure'
+func (r *rollout) flags(f *pflag.FlagSet, rollback bool) {
+	f.BoolVar(&r.Wait, "wait", false, "wait for all tasks of the service to run the new image after updating")
+	f.DurationVar(&r.WaitTimeout, "wait-timeout", 5*time.Minute, "how long to wait for the service to roll out")
+	if rollback {
+		f.BoolVar(&r.Rollback, "rollback-on-failure", true, "roll back the service to the previous image if the rollout fails, only works with --wait")
+	}
+}
+
+// updateService 更新 service 的 image, wait 時會等到所有 tasks 都以新的 image 執行, 失敗時依照 rollback 回復到更新前的 image
+func updateService(url, serviceID string, image *docker.Image, skipSlack bool, r rollout) error {
+	d := newDeployer(url)
+	previous, err := deploy(d, serviceID, image, skipSlack, r.Wait && r.Rollback)
+	if err != nil {
+		return err
+	}
+	if !r.Wait {
+		return nil
+	}
+	err = d.WaitForRollout(serviceID, image, r.WaitTimeout, rolloutInterval)
+	if err == nil || !r.Rollback {
+		return err
+	}
+	logrus.Errorf("Rollout of %s failed: %s", image, err)
+	if _, rerr := deploy(d, serviceID, previous, skipSlack, false); rerr != nil {
+		return fmt.Errorf("%s, and failed to roll back to %s: %s", err, previous, rerr)
+	}
+	return fmt.Errorf("%s, service %s has been rolled back to %s", err, serviceID, previous)
+}
+
+// deploy 將 service 更新為 image, 更新前會將 service 當前的 image 記錄到 $HOME/.s2i/deployments 中, 回傳更新前的 image;
+// 記錄只是為了方便日後 rollback, 失敗時只會警告, 除非 required (如 rollout 失敗要自動回復) 才會中止更新
+func deploy(d *deployer.Client, serviceID string, image *docker.Image, skipSlack, required bool) (*docker.Image, error) {
+	previous, err := recordPrevious(d, serviceID, image)
+	if err != nil {
+		if required {
+			return nil, err
+		}
+		logrus.Warnf("%s, 'rollback' may not find the previous image of service %s", err, serviceID)
+	}
+	if err := d.UpdateService(serviceID, image, skipSlack); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// recordPrevious 查詢 service 當前的 image 並記錄到 $HOME/.s2i/deployments 中, 寫入記錄失敗只會警告並仍回傳查到的 image
+func recordPrevious(d *deployer.Client, serviceID string, image *docker.Image) (*docker.Image, error) {
+	s, err := d.InspectService(serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect service %s: %s", serviceID, err)
+	}
+	previous, err := docker.ParseImage(s.Image())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image of service %s: %s", serviceID, err)
+	}
+	path, err := deployer.HistoryPath(serviceID)
+	if err != nil {
+		logrus.Warnf("failed to record the previous image of service %s: %s", serviceID, err)
+		return previous, nil
+	}
+	if err := deployer.NewHistory(path).Append(&deployer.Deployment{
+		UpdatedAt: time.Now(),
+		ServiceID: serviceID,
+		Image:     image.String(),
+		Previous:  s.Image(),
+	}); err != nil {
+		logrus.Warnf("failed to record the previous image of service %s in %s: %s", serviceID, path, err)
+		return previous, nil
+	}
+	logrus.Debugf("previous image %s of service %s is recorded in %s", previous, serviceID, path)
+	return previous, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/git"
+)
+
+// useRemote 依照 git remote 調整這次執行的 global 設定
+func useRemote(r *git.Remote) {
+	if len(r.Credential) != 0 { // 代表此 repo 是用指定 token clone 的, 因此換掉這次 global 的 token
+		token = r.Credential
+	}
+	remoteHost = r.Host
+}
+
+// commitish 回傳建立 tag 的目標, 若 branch 沒有被調整過, 就直接使用當前的 commit, 確保 tag 打在實際 build 的 source 上
+func commitish(head *git.Head, branch string) string {
+	if head != nil && head.Commit != "" && head.Branch == branch {
+		return head.Commit
+	}
+	return branch
+}
+
+// checkHead 確認當前的 commit 已經 push 到 remote 上, 否則 tag 將無法對應到實際 build 的 source
+func checkHead(head *git.Head, owner, repo, branch string, skipPushCheck bool) error {
+	if head == nil || head.Commit == "" || head.Branch != branch { // 使用者自行指定了 branch, 就以 remote 上的 branch 為主
+		return nil
+	}
+	if head.Dirty {
+		logrus.Warnf("working tree has uncommitted changes, they will not be included in the tag")
+	}
+	if skipPushCheck {
+		return nil
+	}
+	s, err := newSCM(owner, repo)
+	if err != nil {
+		return err
+	}
+	pushed, err := s.CommitExists(head.Commit)
+	if err != nil {
+		return err
+	}
+	if !pushed {
+		return fmt.Errorf("commit %s has not been pushed to %s/%s yet, please push it first or pass '--skip-push-check' to skip the check", head.Commit, owner, repo)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
+	"io/ioutil"
+)
+
+// releaseNotes 產生 tag 的 release notes, 有指定 notesFile 時以檔案內容為準
+// 自動產生失敗時只會提醒, 回傳 nil 代表不填寫 release notes
+func releaseNotes(s scm.SCM, notesFile, tag, head string) *scm.Notes {
+	if notesFile != "" {
+		b, err := ioutil.ReadFile(notesFile)
+		if err != nil {
+			logrus.Warnf("failed to read release notes from %s: %s", notesFile, err)
+			return nil
+		}
+		return &scm.Notes{Name: tag, Body: string(b)}
+	}
+	notes, err := scm.GenerateNotes(logrus.StandardLogger(), s, tag, head)
+	if err != nil {
+		logrus.Warnf("failed to generate release notes: %s", err)
+		return nil
+	}
+	return notes
+}
+
+// printNotes 印出 release notes 的 markdown, 方便在建立 release 前預覽
+func printNotes(notes *scm.Notes) {
+	if notes == nil {
+		return
+	}
+	logrus.Printf("# %s\n\n%s", notes.Name, notes.Body)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/softleader/s2i/pkg/deployer"
+	"github.com/softleader/s2i/pkg/scm"
+	"gopkg.in/yaml.v2"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputName  = "name"
+)
+
+// checkOutput 檢查 '--output' 是否為支援的格式
+func checkOutput(output string) error {
+	switch output {
+	case outputTable, outputJSON, outputYAML, outputName:
+		return nil
+	}
+	return fmt.Errorf("unsupported output %q, must be one of: %s, %s, %s, %s", output, outputTable, outputJSON, outputYAML, outputName)
+}
+
+// printReleases 依照 output 格式將 releases 印到 w
+func printReleases(w io.Writer, output string, releases []*scm.Release) error {
+	if releases == nil {
+		releases = []*scm.Release{} // 讓 json 及 yaml 印出空陣列而非 null
+	}
+	switch output {
+	case outputJSON, outputYAML:
+		return printObject(w, output, releases)
+	case outputName:
+		for _, r := range releases {
+			if _, err := fmt.Fprintln(w, r.TagName); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TAG\tNAME\tPRERELEASE\tDRAFT\tCOMMITISH\tPUBLISHED\tAUTHOR\tURL\tSTATUS")
+	for _, r := range releases {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.TagName, r.Name, strconv.FormatBool(r.Prerelease), strconv.FormatBool(r.Draft),
+			r.TargetCommitish, formatTime(r.PublishedAt), r.Author, r.HTMLURL, r.Status)
+	}
+	return tw.Flush()
+}
+
+// printServices 依照 output 格式將 services 印到 w
+func printServices(w io.Writer, output string, services []deployer.DockerService) error {
+	if services == nil {
+		services = []deployer.DockerService{} // 讓 json 及 yaml 印出空陣列而非 null
+	}
+	switch output {
+	case outputJSON, outputYAML:
+		return printObject(w, output, services)
+	case outputName:
+		for _, s := range services {
+			if _, err := fmt.Fprintln(w, s.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tIMAGE\tMODE\tREPLICAS\tPORTS")
+	for _, s := range services {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.Name, s.Image, s.Mode, s.Replicas, s.Ports)
+	}
+	return tw.Flush()
+}
+
+// printObject 將 v 以 json 或 yaml 格式印到 w
+func printObject(w io.Writer, output string, v interface{}) error {
+	if output == outputJSON {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
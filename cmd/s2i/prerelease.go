@@ -1,46 +1,87 @@
 package main
 
 import (
-	"errors"
 	"github.com/sirupsen/logrus"
-	"github.com/softleader/s2i/pkg/deployer"
 	"github.com/softleader/s2i/pkg/docker"
-	"github.com/softleader/s2i/pkg/github"
+	"github.com/softleader/s2i/pkg/git"
 	"github.com/softleader/s2i/pkg/jib"
 	"github.com/softleader/s2i/pkg/mvn"
+	"github.com/softleader/s2i/pkg/scm"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"os"
+	"strconv"
 )
 
 const pluginPrereleaseDesc = `Draft a pre-release to SoftLeader docker swarm ecosystem
 
 建立 pre-release 版本, pre 為此 command 的縮寫, 傳入 '--interactive' 可以開啟互動模式
-在互動模式下, tag 若不傳入就會自動的到 GitHub 找出 latest release 並增加一個 patch 版號做為問答預設的 tag:
+在互動模式下, tag 若不傳入就會自動的到 GitHub 找出 latest release 並增加版號做為問答預設的 tag
+預設會依照 latest release 之後的 conventional commits 決定增加的版號: 有 breaking change ('!' 或 'BREAKING CHANGE:') 增加 major,
+有 'feat' 增加 minor, 其餘增加 patch, 也可以透過 '--bump' 指定 (auto, major, minor 或 patch), 指定 '--bump' 時 tag 也可以不傳入:
 
 	$ s2i prerelease TAG
 	$ s2i pre -i
+	$ s2i pre --bump minor
 
-pre-release 必須指定 stage, 預設為 '0', 基本上是建議:
+pre-release 必須指定 stage, 預設為 'alpha', 可傳入 'alpha', 'beta' 或 'rc', 預設對應到 tag 上的 identifier 為:
 
 	- 0 for alpha
 	- 1 for beta
 	- 2 for release candidate
 
-你可以透過 '--stage' 調整, 可以傳入任意字串:
+identifier 可以在設定檔的 'stages' 下調整, 同一個版本的 stage 只能依照 alpha, beta, rc 的順序前進, 如已有 rc 就不能再建立 beta
+你可以透過 '--stage' 調整, 也可以傳入只包含英數字及 '-' 的字串做為 identifier (不做順序檢查):
 
-	$ s2i pre TAG --stage do.not.use
+	$ s2i pre TAG --stage rc
+	$ s2i pre TAG --stage hotfix
+
+建置前會先確認 registry 上還沒有相同 tag 的 image, 已存在時將會中止以確保已發佈的版號不會被覆蓋, 可傳入 '--overwrite-image' 強制覆蓋;
+'--force' 只會在 tag 已存在時先刪除 tag 再重新建立, 不會略過 image 的檢查
+
+重複對同一個版本執行 pre-release 時, 可傳入 '--increment' 依照已存在的 tags 自動在 stage 後增加編號, 不會覆蓋已發佈的 image:
+
+	$ s2i pre 1.2.3 --increment              # 1.2.3-0.1, 1.2.3-0.2, ...
+	$ s2i pre 1.2.3 --increment --stage rc   # 1.2.3-2.1, 1.2.3-2.2, ...
 
 s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調整:
 
-	- git 資訊: '--source-owner', '--source-repo' 及 '--source-branch'
+	- git 資訊: '--source-owner', '--source-repo' 及 '--source-branch', 預設從名為 origin 的 remote 收集, 可傳入 '--remote' 指定
 	- jib 資訊: '--jib-auth-username' 及 '--jib-auth-password'
 
+若沒有調整 '--source-branch', pre-release 的 tag 會直接建立在當前的 commit 上, 因此 s2i 會先確認該 commit 已經 push 到 GitHub,
+尚未 push 時將會中止, 可傳入 '--skip-push-check' 略過此檢查
+
+建立 pre-release 時會比較前一版 release 之間的 commits 及 pull requests, 依照 conventional commit type 或 PR label 分組後自動產生 release notes
+可傳入 '--notes-file' 改用檔案內容, 或傳入 '--notes-only' 只預覽 release notes 而不建立 pre-release:
+
+	$ s2i pre TAG --notes-only
+
+常用的 flag 可以寫在專案的 .s2i.yaml 或 $HOME/.s2i.yaml 的 'prerelease' 下, 執行 'config view' 可查看合併後的結果:
+
+	$ s2i config view
+
 傳入 '--service-id' 即可在最後自動的更新 SoftLeader Deployer 上的服務
 當然你必須先到 SoftLeader Deployer (http://softleader.com.tw:5678) 上查出要更新的 Service ID
 或是開啟互動模式來協助你選到 Service ID:
 
 	$ s2i pre TAG --service-id SERVICE_ID
 
+同一個 image 執行在多個服務時, '--service-id' 可以重複傳入, 或以 '--service-label' 篩選 Deployer 上的服務,
+預設依序更新, 可傳入 '--parallel' 同時更新多個服務, 最後會列出每個服務的結果
+
+	$ s2i pre TAG --service-id SERVICE_ID_1 --service-id SERVICE_ID_2
+	$ s2i pre TAG --service-label app=foo --parallel 3
+
+再傳入 '--wait' 會持續查詢 Deployer, 直到服務的所有 tasks 都以新的 image 執行, 更新失敗, tasks 不斷重啟或超過 '--wait-timeout' (預設 5m) 時以非 0 結束
+
+	$ s2i pre TAG --service-id SERVICE_ID --wait
+
+更新前 s2i 會記錄服務當前的 image, '--wait' 時若 rollout 失敗會自動更新回該 image, 可傳入 '--rollback-on-failure=false' 關閉,
+之後也可以執行 'rollback' 手動回復
+
+	$ s2i rollback --service-id SERVICE_ID
+
 如果你當前的專案並非 maven 專案 (如 nodejs), 請務必使用 multi-stage builds 來建構 source code
 (https://docs.docker.com/develop/develop-images/multistage-build/)
 s2i 會自動判斷 multi-stage build 等專案建構條件, 在 jib 及 docker 之間自動的挑選 shipping source 的策略
@@ -58,7 +99,8 @@ s2i 會自動判斷 multi-stage build 等專案建構條件, 在 jib 及 docker
 `
 
 type prereleaseCmd struct {
-	Force           bool
+	Force           bool `yaml:"-"`
+	OverwriteImage  bool `yaml:"-"`
 	interactive     bool
 	promptSize      int
 	SourceOwner     string `yaml:"source-owner"`
@@ -69,20 +111,27 @@ type prereleaseCmd struct {
 	UpdateSnapshots bool   `yaml:"update-snapshots"`
 	ConfigServer    string `yaml:"config-server"`
 	ConfigLabel     string `yaml:"config-label"`
-	Image           *docker.SoftleaderHubImage
+	Image           *docker.Image
 	Stage           string
 	Deployer        string
 	Auth            *jib.Auth
-	ServiceID       string `yaml:"service-id"`
-	ShipStrategy    int    `yaml:"build-strategy"`
-	SkipSlack       bool   `yaml:"skip-slack"`
+	services        `yaml:",inline"`
+	ShipStrategy    int  `yaml:"build-strategy"`
+	SkipSlack       bool `yaml:"skip-slack"`
+	rollout         `yaml:",inline"`
+	SkipPushCheck   bool   `yaml:"skip-push-check"`
+	NotesFile       string `yaml:"notes-file"`
+	Bump            string
+	Increment       bool
+	notesOnly       bool
+	head            *git.Head
 	pwd             string
 }
 
 func newPrereleaseCmd() *cobra.Command {
 	c := &prereleaseCmd{
 		Auth:  &jib.Auth{},
-		Image: &docker.SoftleaderHubImage{},
+		Image: &docker.Image{},
 	}
 	cmd := &cobra.Command{
 		Use:     "prerelease <TAG>",
@@ -91,43 +140,32 @@ func newPrereleaseCmd() *cobra.Command {
 		Long:    pluginPrereleaseDesc,
 		Args:    cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			if !c.interactive && len(args) < 1 {
-				return errors.New(`accepts 1 arg(s), received 0`)
+			if err := c.loadConfig(cmd.Flags()); err != nil {
+				return err
 			}
 			if len(args) > 0 {
 				c.Image.Tag = args[0]
-			}
-			if c.pwd, err = os.Getwd(); err == nil {
-				var t string
-				t, c.SourceOwner, c.SourceRepo = github.Remote(logrus.StandardLogger(), c.pwd)
-				if len(t) != 0 { // 代表此 repo 是用指定 token clone 的, 因此換掉這次 global 的 token
-					token = t
-				}
-				c.Image.Name = c.SourceRepo
-				c.SourceBranch = github.Head(logrus.StandardLogger(), c.pwd)
-				c.Auth = jib.GetAuth(logrus.StandardLogger(), c.pwd)
+			} else if c.Image.Tag, err = bumpVersion(c.SourceOwner, c.SourceRepo, c.Bump, commitish(c.head, c.SourceBranch), c.interactive); err != nil {
+				return err
 			}
 			if c.interactive {
-				if c.Image.Tag == "" {
-					var err error
-					c.Image.Tag, err = github.FindNextReleaseVersion(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo)
-					if err != nil {
-						logrus.Debugln(err)
-					}
-				}
 				if err := prereleaseQuestions(c); err != nil {
 					return err
 				}
 			}
-			if err := c.Image.CheckValid(); err != nil {
+			if err := checkImage(c.Image); err != nil {
 				return err
 			}
 			return c.run()
 		},
 	}
+	c.flags(cmd.Flags())
+	return cmd
+}
 
-	f := cmd.Flags()
+func (c *prereleaseCmd) flags(f *pflag.FlagSet) {
 	f.BoolVarP(&c.Force, "force", "f", false, "force to delete the tag if it already exists")
+	f.BoolVar(&c.OverwriteImage, "overwrite-image", false, "overwrite the image if it already exists in the docker registry")
 	f.BoolVarP(&c.interactive, "interactive", "i", false, "interactive prompt")
 	f.IntVar(&c.promptSize, "interactive-prompt-size", 7, "interactive prompt size")
 	f.BoolVar(&c.SkipTests, "skip-tests", false, "skip tests when building image")
@@ -139,34 +177,85 @@ func newPrereleaseCmd() *cobra.Command {
 	f.StringVar(&c.ConfigServer, "config-server", "http://softleader.com.tw:8887", "config server to run the test")
 	f.StringVar(&c.ConfigLabel, "config-label", "", "the label of config server to run the test, e.g. sqlServer")
 	f.StringVar(&c.Image.Name, "image", c.Image.Name, "name of image to build")
-	f.StringVar(&c.Stage, "stage", "0", "designating development stage to build, e.g. 0 for alpha, 1 for beta, 2 for release candidate")
+	f.StringVar(&c.Stage, "stage", scm.StageAlpha, "designating development stage to build, one of: alpha, beta, rc, or any pre-release identifier")
 	f.StringVar(&c.Deployer, "deployer", "http://softleader.com.tw:5678", "deployer to deploy")
 	f.StringVar(&c.Auth.Username, "jib-auth-username", "", "username of docker registry for jib")
 	f.StringVar(&c.Auth.Password, "jib-auth-password", "", "password of docker registry for jib")
-	f.StringVar(&c.ServiceID, "service-id", "", "docker swarm service id to update")
+	c.services.flags(f)
 	f.IntVarP(&c.ShipStrategy, "ship-strategy", "S", 0, "specify how to ship source, 0 for auto-detect, 1 for jib, 2 for docker")
 	f.BoolVar(&c.SkipSlack, "skip-slack", false, "skip slack webhook")
-	return cmd
+	c.rollout.flags(f, true)
+	f.BoolVar(&c.SkipPushCheck, "skip-push-check", false, "skip checking if the current commit has been pushed to GitHub")
+	f.StringVar(&c.NotesFile, "notes-file", "", "read release notes from file instead of generating from commits and pull requests")
+	f.BoolVar(&c.notesOnly, "notes-only", false, "print the release notes and exit without releasing")
+	f.BoolVar(&c.Increment, "increment", false, "auto-increment pre-release number of the stage from existing tags, e.g. 1.2.3-0.1, 1.2.3-0.2")
+	f.StringVar(&c.Bump, "bump", "", "bump version from latest release when tag is not specified, one of: auto, major, minor, patch (default auto in interactive mode)")
+}
+
+// loadConfig 從當前目錄收集專案資訊, 再依序合併設定檔, 環境變數及 flags
+func (c *prereleaseCmd) loadConfig(f *pflag.FlagSet) (err error) {
+	changed := changedFlags(f)
+	if c.pwd, err = os.Getwd(); err == nil {
+		if r, err := git.FindRemote(logrus.StandardLogger(), c.pwd, remote); err != nil {
+			logrus.Debugln(err)
+		} else {
+			useRemote(r)
+			c.SourceOwner, c.SourceRepo = r.Owner, r.Repo
+		}
+		c.Image.Name = c.SourceRepo
+		if h, err := git.ResolveHead(logrus.StandardLogger(), c.pwd); err != nil {
+			logrus.Debugln(err)
+		} else {
+			c.head = h
+			c.SourceBranch = h.Branch
+		}
+		*c.Auth = *jib.GetAuth(logrus.StandardLogger(), c.pwd) // 保留原本的 pointer, 因為 flags 是綁定在上面的
+	}
+	c.Image.Registry, c.Image.Namespace = imageRegistry, imageNamespace // 可以再被 command 下的 image 設定覆蓋
+	return mergeConfig(f, changed, c.pwd, "prerelease", c)
 }
 
 func (c *prereleaseCmd) run() (err error) {
+	if err := c.setPreRelease(); err != nil {
+		return err
+	}
+	if c.notesOnly {
+		s, err := newSCM(c.SourceOwner, c.SourceRepo)
+		if err != nil {
+			return err
+		}
+		printNotes(releaseNotes(s, c.NotesFile, c.Image.Tag, commitish(c.head, c.SourceBranch)))
+		return nil
+	}
+	if !c.SkipDraft {
+		if err := checkHead(c.head, c.SourceOwner, c.SourceRepo, c.SourceBranch, c.SkipPushCheck); err != nil {
+			return err
+		}
+	}
+	if err := checkImageNotExists(c.Auth, c.Image, c.OverwriteImage, "overwrite-image"); err != nil {
+		return err
+	}
 	if !c.SkipTests {
 		if err := mvn.Test(logrus.StandardLogger(), c.ConfigServer, c.ConfigLabel, c.UpdateSnapshots); err != nil {
 			return err
 		}
 	}
-	c.Image.SetPreRelease(c.Stage)
-
 	if err := c.ship(); err != nil {
 		return err
 	}
 	if !c.SkipDraft {
-		if _, err = github.CreatePrerelease(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.SourceBranch, c.Image.Tag, c.Force); err != nil {
+		s, err := newSCM(c.SourceOwner, c.SourceRepo)
+		if err != nil {
+			return err
+		}
+		ref := commitish(c.head, c.SourceBranch)
+		notes := releaseNotes(s, c.NotesFile, c.Image.Tag, ref)
+		if _, err = s.CreatePrerelease(ref, c.Image.Tag, notes, c.Force); err != nil {
 			return err
 		}
 	}
-	if c.ServiceID != "" {
-		if err := deployer.UpdateService(logrus.StandardLogger(), "s2i", metadata.String(), c.Deployer, c.ServiceID, c.Image, c.SkipSlack); err != nil {
+	if !c.services.isEmpty() {
+		if err := updateServices(c.Deployer, c.services, c.Image, c.SkipSlack, c.rollout); err != nil {
 			return err
 		}
 	}
@@ -174,6 +263,38 @@ func (c *prereleaseCmd) run() (err error) {
 	return nil
 }
 
+// setPreRelease 將 tag 加上 stage 對應的 identifier, 並確認 stage 只會往前進
+// increment 時會再加上從已存在的 tags 中找出的下一個編號, 避免覆蓋已發佈的 image
+func (c *prereleaseCmd) setPreRelease() error {
+	identifier := stages.Identifier(c.Stage)
+	if c.SkipDraft && !c.Increment {
+		return c.Image.SetPreRelease(identifier)
+	}
+	s, err := newSCM(c.SourceOwner, c.SourceRepo)
+	if err != nil {
+		return err
+	}
+	tags, err := s.ListTags()
+	if err != nil {
+		return err
+	}
+	if err := stages.CheckForward(tags, c.Image.Tag, c.Stage); err != nil {
+		return err
+	}
+	if !c.Increment {
+		return c.Image.SetPreRelease(identifier)
+	}
+	n, err := scm.NextPrereleaseNumber(tags, c.Image.Tag, identifier)
+	if err != nil {
+		return err
+	}
+	if err := c.Image.SetPreRelease(identifier, strconv.FormatUint(n, 10)); err != nil {
+		return err
+	}
+	logrus.Debugf("auto-incremented pre-release tag to %s", c.Image.Tag)
+	return nil
+}
+
 func (c *prereleaseCmd) ship() error {
 	if c.ShipStrategy == 1 { // jib
 		return c.jibRelease()
@@ -14,15 +14,25 @@ func prereleaseQuestions(c *prereleaseCmd) error {
 		return err
 	}
 
-	if err := prompt.AskYesNo("Force to delete the tag if it already exists?", "y", &c.Force); err != nil {
+	if err := prompt.AskYesNoBool("Auto-increment pre-release number from existing tags?", c.Increment, &c.Increment); err != nil {
 		return err
 	}
 
+	if !c.Increment {
+		if err := prompt.AskYesNoBool("Force to delete the tag if it already exists?", c.Force, &c.Force); err != nil {
+			return err
+		}
+
+		if err := prompt.AskYesNoBool("Overwrite the image if it already exists in the docker registry?", c.OverwriteImage, &c.OverwriteImage); err != nil {
+			return err
+		}
+	}
+
 	if err := prompt.AskYesNo("Force to check for updated snapshots on remote repositories?", "n", &c.UpdateSnapshots); err != nil {
 		return err
 	}
 
-	if err := prompt.AskServiceID(logrus.StandardLogger(), "s2i", metadata.String(), c.Deployer, c.Image.Name, c.ServiceID, c.promptSize, &c.ServiceID); err != nil {
+	if err := prompt.AskServiceID(newDeployer(c.Deployer), c.Image.Name, c.ServiceIDs, c.promptSize, (*[]string)(&c.ServiceIDs)); err != nil {
 		return err
 	}
 
@@ -46,7 +56,7 @@ func prereleaseQuestions(c *prereleaseCmd) error {
 		return err
 	}
 
-	if err := prompt.AskRequired("Designating development stage to build, e.g. 0 for alpha, 1 for beta, 2 for release candidate", c.Stage, &c.Stage); err != nil {
+	if err := prompt.AskRequired("Designating development stage to build, one of: alpha, beta, rc, or any pre-release identifier", c.Stage, &c.Stage); err != nil {
 		return err
 	}
 
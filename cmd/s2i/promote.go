@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/docker"
+	"github.com/softleader/s2i/pkg/git"
+	"github.com/softleader/s2i/pkg/jib"
+	"github.com/softleader/s2i/pkg/registry"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+)
+
+const pluginPromoteDesc = `將測試過的 pre-release 升級為正式的 release, 不會重新 build image
+
+	$ s2i promote TAG
+
+如 'promote 1.2.3-0' 會:
+
+	1. 在 registry (預設為 hub.softleader.com.tw, 可透過 '--registry' 調整) 上將 image 1.2.3-0 的 manifest 複製為 1.2.3, 不需要 docker pull, 也不會重新 build, 因此與測試過的 image 完全相同
+	2. 在 pre-release 1.2.3-0 所在的 commit 上建立 release 1.2.3
+	3. 有傳入 '--service-id' 時, 更新 SoftLeader Deployer 上的服務, 再傳入 '--wait' 會等到服務的所有 tasks 都以新的 image 執行
+
+registry 的帳密預設會試著從 Jenkinsfile 中的 jib 設定取得, 再來是 docker login 的帳密 ($HOME/.docker/config.json),
+也可以透過 '--registry-username' 及 '--registry-password' 傳入
+
+正式版號的 image 已存在且與 pre-release 不同時將會中止, 以確保已發佈的版號不會被覆蓋, 可傳入 '--force' 強制覆蓋
+
+建立 release 時會比較前一版 release 之間的 commits 及 pull requests 自動產生 release notes, 可傳入 '--notes-file' 改用檔案內容
+
+s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調整:
+
+	- git 資訊: '--source-owner', '--source-repo', 預設從名為 origin 的 remote 收集, 可傳入 '--remote' 指定
+
+常用的 flag 可以寫在專案的 .s2i.yaml 或 $HOME/.s2i.yaml 的 'promote' 下
+
+可以使用 '--help' 查看所有選項及其詳細說明
+
+	$ s2i promote -h
+`
+
+type promoteCmd struct {
+	SourceOwner string `yaml:"source-owner"`
+	SourceRepo  string `yaml:"source-repo"`
+	Image       *docker.Image
+	Auth        *jib.Auth
+	Deployer    string
+	services    `yaml:",inline"`
+	SkipSlack   bool `yaml:"skip-slack"`
+	rollout     `yaml:",inline"`
+	NotesFile   string `yaml:"notes-file"`
+	Force       bool   `yaml:"-"`
+}
+
+func newPromoteCmd() *cobra.Command {
+	c := &promoteCmd{
+		Auth:  &jib.Auth{},
+		Image: &docker.Image{},
+	}
+	cmd := &cobra.Command{
+		Use:   "promote <TAG>",
+		Short: "promote a pre-release to a final release without rebuilding",
+		Long:  pluginPromoteDesc,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.loadConfig(cmd.Flags()); err != nil {
+				return err
+			}
+			c.Image.Tag = args[0]
+			if err := checkImage(c.Image); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	c.flags(cmd.Flags())
+	return cmd
+}
+
+func (c *promoteCmd) flags(f *pflag.FlagSet) {
+	f.StringVar(&c.SourceOwner, "source-owner", c.SourceOwner, "name of the owner (user or org) of the repo to create release")
+	f.StringVar(&c.SourceRepo, "source-repo", c.SourceRepo, "name of repo to create release")
+	f.StringVar(&c.Image.Name, "image", c.Image.Name, "name of image to promote")
+	f.StringVar(&c.Auth.Username, "registry-username", "", "username of docker registry")
+	f.StringVar(&c.Auth.Password, "registry-password", "", "password of docker registry")
+	f.StringVar(&c.Deployer, "deployer", "http://softleader.com.tw:5678", "deployer to deploy")
+	c.services.flags(f)
+	f.BoolVar(&c.SkipSlack, "skip-slack", false, "skip slack webhook")
+	c.rollout.flags(f, true)
+	f.StringVar(&c.NotesFile, "notes-file", "", "read release notes from file instead of generating from commits and pull requests")
+	f.BoolVarP(&c.Force, "force", "f", false, "overwrite the image of the final version if it already exists in the docker registry")
+}
+
+// loadConfig 從當前目錄收集專案資訊, 再依序合併設定檔, 環境變數及 flags
+func (c *promoteCmd) loadConfig(f *pflag.FlagSet) error {
+	changed := changedFlags(f)
+	pwd, err := os.Getwd()
+	if err == nil {
+		if r, err := git.FindRemote(logrus.StandardLogger(), pwd, remote); err != nil {
+			logrus.Debugln(err)
+		} else {
+			useRemote(r)
+			c.SourceOwner, c.SourceRepo = r.Owner, r.Repo
+		}
+		c.Image.Name = c.SourceRepo
+		*c.Auth = *jib.GetAuth(logrus.StandardLogger(), pwd) // 保留原本的 pointer, 因為 flags 是綁定在上面的
+	}
+	c.Image.Registry, c.Image.Namespace = imageRegistry, imageNamespace // 可以再被 command 下的 image 設定覆蓋
+	return mergeConfig(f, changed, pwd, "promote", c)
+}
+
+func (c *promoteCmd) run() error {
+	prerelease := c.Image.Tag
+	final, err := scm.FinalVersion(prerelease)
+	if err != nil {
+		return err
+	}
+	s, err := newSCM(c.SourceOwner, c.SourceRepo)
+	if err != nil {
+		return err
+	}
+	sha, err := s.ResolveTag(prerelease)
+	if err != nil {
+		if err == scm.ErrNotFound {
+			return fmt.Errorf("pre-release %s not found on %s/%s", prerelease, c.SourceOwner, c.SourceRepo)
+		}
+		return err
+	}
+	if _, err := s.ResolveTag(final); err == nil {
+		return fmt.Errorf("%s has already been released on %s/%s", final, c.SourceOwner, c.SourceRepo)
+	} else if err != scm.ErrNotFound {
+		return err
+	}
+
+	r := newRegistry(c.Image.Host(), c.Auth)
+	if err := c.checkFinalImage(r, prerelease, final); err != nil {
+		return err
+	}
+	m, err := r.Retag(c.Image.Repository(), prerelease, final)
+	if err != nil {
+		return err
+	}
+	c.Image.Tag = final
+	logrus.Printf("Image %s has been promoted from %s (%s)", c.Image, prerelease, m.Digest)
+
+	if _, err := s.CreateRelease(sha, final, releaseNotes(s, c.NotesFile, final, sha)); err != nil {
+		return err
+	}
+	logrus.Printf("Release %s has been created on %s", final, sha)
+
+	if !c.services.isEmpty() {
+		if err := updateServices(c.Deployer, c.services, c.Image, c.SkipSlack, c.rollout); err != nil {
+			return err
+		}
+	}
+	logrus.Printf("Everything is all set, you are good to go.")
+	return nil
+}
+
+// checkFinalImage 確認正式版號的 image 還不存在, 已存在且與 pre-release 相同時 (如上次 promote 到一半中斷) 可以繼續, 不同時除非 force 否則中止
+func (c *promoteCmd) checkFinalImage(r *registry.Client, prerelease, final string) error {
+	dst, err := r.ManifestDigest(c.Image.Repository(), final)
+	if err == registry.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	src, err := r.ManifestDigest(c.Image.Repository(), prerelease)
+	if err != nil {
+		return err
+	}
+	if src == dst {
+		logrus.Debugf("image %s:%s is already the same as %s", c.Image.Repository(), final, prerelease)
+		return nil
+	}
+	if c.Force {
+		logrus.Warnf("image %s:%s already exists, it will be overwritten", c.Image.Repository(), final)
+		return nil
+	}
+	return fmt.Errorf("image %s:%s already exists and differs from %s, versions are immutable, pass '--force' to overwrite it", c.Image.Repository(), final, prerelease)
+}
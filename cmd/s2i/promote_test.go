@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"github.com/softleader/s2i/pkg/docker"
+	"github.com/softleader/s2i/pkg/jib"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPromote_GitHub(t *testing.T) {
+	var released, retagged string
+	mux := http.NewServeMux()
+	// GitHub 上只有 1.2.3-0, 查詢 tags/1.2.3 時 GitHub 會回傳所有以此開頭的 refs
+	mux.HandleFunc("/api/v3/repos/softleader/app/git/refs/tags/", func(w http.ResponseWriter, r *http.Request) {
+		ref := `{"ref":"refs/tags/1.2.3-0","object":{"type":"commit","sha":"c1230"}}`
+		switch strings.TrimPrefix(r.URL.Path, "/api/v3/repos/softleader/app/git/refs/tags/") {
+		case "1.2.3-0":
+			fmt.Fprint(w, ref)
+		case "1.2.3":
+			fmt.Fprintf(w, "[%s]", ref)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	})
+	mux.HandleFunc("/api/v3/repos/softleader/app/releases", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			b, _ := ioutil.ReadAll(r.Body)
+			released = string(b)
+			fmt.Fprint(w, `{"tag_name":"1.2.3","target_commitish":"c1230"}`)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/v2/app/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		tag := strings.TrimPrefix(r.URL.Path, "/v2/app/manifests/")
+		switch {
+		case r.Method == http.MethodPut:
+			retagged = tag
+			w.WriteHeader(http.StatusCreated)
+		case tag == "1.2.3-0":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Header().Set("Docker-Content-Digest", "sha256:1230")
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	defer func(provider, url, host string) { scmProvider, scmURL, remoteHost = provider, url, host }(scmProvider, scmURL, remoteHost)
+	scmProvider, scmURL, remoteHost = scmGitHub, server.URL, ""
+
+	c := &promoteCmd{
+		SourceOwner: "softleader",
+		SourceRepo:  "app",
+		Auth:        &jib.Auth{},
+		Image:       &docker.Image{Registry: server.URL, Name: "app", Tag: "1.2.3-0"},
+	}
+	if err := c.run(); err != nil {
+		t.Fatal(err)
+	}
+	if retagged != "1.2.3" {
+		t.Errorf("image should be retagged to 1.2.3, but got %q", retagged)
+	}
+	if !strings.Contains(released, `"tag_name":"1.2.3"`) || !strings.Contains(released, `"target_commitish":"c1230"`) {
+		t.Errorf("release 1.2.3 should be created on c1230, but got %s", released)
+	}
+}
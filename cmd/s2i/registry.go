@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/docker"
+	"github.com/softleader/s2i/pkg/jib"
+	"github.com/softleader/s2i/pkg/registry"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/spf13/pflag"
+	"os"
+)
+
+// newRegistry 建立存取 host 的 docker registry client, auth 無效時改用 docker login 的帳密
+func newRegistry(host string, auth *jib.Auth) *registry.Client {
+	username, password := auth.Username, auth.Password
+	if !auth.IsValid() {
+		username, password = registry.Credentials(logrus.StandardLogger(), host)
+	}
+	return registry.NewClient(logrus.StandardLogger(), host, username, password)
+}
+
+// checkImage 檢查 image 資訊是否有效, 且 tag 為有效的 semver2 版號
+func checkImage(image *docker.Image) error {
+	if err := image.CheckValid(); err != nil {
+		return err
+	}
+	return scm.CheckVersion(image.Tag)
+}
+
+// checkImageNotExists 確認 registry 上還沒有同名 tag 的 image, 確保已發佈的版號不會被覆蓋;
+// overwrite 時只警告而不中止, flag 為允許覆蓋的 flag 名稱, 會顯示在錯誤訊息中
+func checkImageNotExists(auth *jib.Auth, image *docker.Image, overwrite bool, flag string) error {
+	exists, err := newRegistry(image.Host(), auth).Exists(image.Repository(), image.Tag)
+	if err != nil {
+		if overwrite {
+			logrus.Warnf("failed to check if image %s exists: %s", image, err)
+			return nil
+		}
+		return fmt.Errorf("failed to check if image %s exists: %s, pass '--%s' to skip the check", image, err, flag)
+	}
+	if !exists {
+		return nil
+	}
+	if overwrite {
+		logrus.Warnf("image %s already exists, it will be overwritten", image)
+		return nil
+	}
+	return fmt.Errorf("image %s already exists, versions are immutable, pass '--%s' to overwrite it", image, flag)
+}
+
+// tagImage 讓 'tag delete' 及 'tag prune' 可以一併刪除 registry 上同名 tag 的 image
+type tagImage struct {
+	DeleteImage bool `yaml:"delete-image"`
+	Image       string
+	Auth        *jib.Auth `yaml:"registry-auth"`
+}
+
+func (t *tagImage) flags(f *pflag.FlagSet) {
+	t.Auth = &jib.Auth{}
+	f.BoolVar(&t.DeleteImage, "delete-image", false, "also delete the image of the same tag from the docker registry")
+	f.StringVar(&t.Image, "image", "", "name of image to delete (default: name of the repo)")
+	f.StringVar(&t.Auth.Username, "registry-username", "", "username of docker registry")
+	f.StringVar(&t.Auth.Password, "registry-password", "", "password of docker registry")
+}
+
+// wrap 在需要刪除 image 時, 將 s 包裝成刪除 tag 後一併刪除 image 的 scm.SCM
+func (t *tagImage) wrap(s scm.SCM, repo string) scm.SCM {
+	if !t.DeleteImage {
+		return s
+	}
+	image := &docker.Image{Registry: imageRegistry, Namespace: imageNamespace, Name: t.Image}
+	if image.Name == "" {
+		image.Name = repo
+	}
+	auth := t.Auth
+	if !auth.IsValid() {
+		if pwd, err := os.Getwd(); err == nil {
+			auth = jib.GetAuth(logrus.StandardLogger(), pwd)
+		}
+	}
+	logrus.Infof("images of the tags will also be deleted from %s/%s", image.Host(), image.Repository())
+	return registry.Wrap(s, newRegistry(image.Host(), auth), image.Repository())
+}
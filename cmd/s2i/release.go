@@ -1,12 +1,13 @@
 package main
 
 import (
-	"errors"
 	"github.com/sirupsen/logrus"
 	"github.com/softleader/s2i/pkg/docker"
-	"github.com/softleader/s2i/pkg/github"
+	"github.com/softleader/s2i/pkg/git"
 	"github.com/softleader/s2i/pkg/jenkins"
+	"github.com/softleader/s2i/pkg/jib"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -16,14 +17,32 @@ import (
 const pluginReleaseDesc = `Draft a release to SoftLeader docker swarm ecosystem
 
 建立 release 版本, 傳入 '--interactive' 可以開啟互動模式
-在互動模式下, tag 若不傳入就會自動的到 GitHub 找出 latest release 並增加一個 patch 版號做為問答預設的 tag:
+在互動模式下, tag 若不傳入就會自動的到 GitHub 找出 latest release 並增加版號做為問答預設的 tag
+預設會依照 latest release 之後的 conventional commits 決定增加的版號: 有 breaking change ('!' 或 'BREAKING CHANGE:') 增加 major,
+有 'feat' 增加 minor, 其餘增加 patch, 也可以透過 '--bump' 指定 (auto, major, minor 或 patch), 指定 '--bump' 時 tag 也可以不傳入:
 
 	$ s2i release TAG
 	$ s2i release -i
+	$ s2i release --bump minor
 
 s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調整:
 
-	- git 資訊: '--source-owner', '--source-repo' 及 '--source-branch'
+	- git 資訊: '--source-owner', '--source-repo' 及 '--source-branch', 預設從名為 origin 的 remote 收集, 可傳入 '--remote' 指定
+
+若沒有調整 '--source-branch', tag 會直接建立在當前的 commit 上, 因此 s2i 會先確認該 commit 已經 push 到 GitHub,
+尚未 push 時將會中止, 可傳入 '--skip-push-check' 略過此檢查
+
+建立 release 時會比較前一版 release 之間的 commits 及 pull requests, 依照 conventional commit type 或 PR label 分組後自動產生 release notes
+可傳入 '--notes-file' 改用檔案內容, 或傳入 '--notes-only' 只預覽 release notes 而不建立 release:
+
+	$ s2i release TAG --notes-only
+
+常用的 flag 可以寫在專案的 .s2i.yaml 或 $HOME/.s2i.yaml 的 'release' 下, 執行 'config view' 可查看合併後的結果:
+
+	$ s2i config view
+
+觸發 Jenkins 前會先確認 registry 上還沒有相同 tag 的 image, 已存在時將會中止以確保已發佈的版號不會被覆蓋, 可傳入 '--force' 強制覆蓋
+registry 的帳密預設會試著從 Jenkinsfile 中的 jib 設定或 docker login 的設定取得, 也可以透過 '--registry-username' 及 '--registry-password' 傳入
 
 傳入 '--service-id' 即可一併將要更新的 Service ID 傳給 Jenkins Pipeline
 當然你必須先到 SoftLeader Deployer (http://softleader.com.tw:5678) 上查出要更新的 Service ID
@@ -46,74 +65,120 @@ type releaseCmd struct {
 	SourceOwner     string `yaml:"source-owner"`
 	SourceRepo      string `yaml:"source-repo"`
 	SourceBranch    string `yaml:"source-branch"`
-	Image           *docker.SoftleaderHubImage
+	Image           *docker.Image
+	Auth            *jib.Auth
+	Force           bool `yaml:"-"`
 	Jenkins         string
 	Deployer        string
 	ServiceID       string `yaml:"service-id"`
 	SkipSlack       bool   `yaml:"skip-slack"`
+	SkipPushCheck   bool   `yaml:"skip-push-check"`
+	NotesFile       string `yaml:"notes-file"`
+	Bump            string
+	notesOnly       bool
 	SlackWebhookURL string `yaml:"slack-webhook-url"`
+	head            *git.Head
 }
 
 func newReleaseCmd() *cobra.Command {
 	c := &releaseCmd{
-		Image: &docker.SoftleaderHubImage{},
+		Auth:  &jib.Auth{},
+		Image: &docker.Image{},
 	}
 	cmd := &cobra.Command{
 		Use:   "release <TAG>",
 		Short: "draft a release version",
 		Long:  pluginReleaseDesc,
 		Args:  cobra.MaximumNArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if !c.interactive && len(args) < 1 {
-				return errors.New(`accepts 1 arg(s), received 0`)
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err := c.loadConfig(cmd.Flags()); err != nil {
+				return err
 			}
 			if len(args) > 0 {
 				c.Image.Tag = args[0]
-			}
-			if pwd, err := os.Getwd(); err == nil {
-				var t string
-				t, c.SourceOwner, c.SourceRepo = github.Remote(logrus.StandardLogger(), pwd)
-				if len(t) != 0 {
-					token = t // 代表此 repo 是用指定 token clone 的, 因此換掉這次 global 的 token
-				}
-				c.Image.Name = c.SourceRepo
-				c.SourceBranch = github.Head(logrus.StandardLogger(), pwd)
+			} else if c.Image.Tag, err = bumpVersion(c.SourceOwner, c.SourceRepo, c.Bump, commitish(c.head, c.SourceBranch), c.interactive); err != nil {
+				return err
 			}
 			if c.interactive {
-				if c.Image.Tag == "" {
-					var err error
-					c.Image.Tag, err = github.FindNextReleaseVersion(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo)
-					if err != nil {
-						logrus.Debugln(err)
-					}
-				}
 				if err := releaseQuestions(c); err != nil {
 					return err
 				}
 			}
-			if err := c.Image.CheckValid(); err != nil {
+			if err := checkImage(c.Image); err != nil {
 				return err
 			}
 			return c.run()
 		},
 	}
+	c.flags(cmd.Flags())
+	return cmd
+}
 
-	f := cmd.Flags()
+func (c *releaseCmd) flags(f *pflag.FlagSet) {
 	f.BoolVarP(&c.interactive, "interactive", "i", false, "interactive prompt")
 	f.IntVar(&c.promptSize, "interactive-prompt-size", 7, "interactive prompt size")
 	f.StringVar(&c.SourceOwner, "source-owner", c.SourceOwner, "name of the owner (user or org) of the repo to create tag")
 	f.StringVar(&c.SourceRepo, "source-repo", c.SourceRepo, "name of repo to create tag")
 	f.StringVar(&c.SourceBranch, "source-branch", c.SourceBranch, "name of branch to create tag")
 	f.StringVar(&c.Image.Name, "image", c.Image.Name, "name of image to build")
+	f.StringVar(&c.Auth.Username, "registry-username", "", "username of docker registry to check if the image already exists")
+	f.StringVar(&c.Auth.Password, "registry-password", "", "password of docker registry to check if the image already exists")
+	f.BoolVarP(&c.Force, "force", "f", false, "release even if the image already exists in the docker registry")
 	f.StringVar(&c.Jenkins, "jenkins", "https://jenkins.softleader.com.tw", "jenkins to run the pipeline")
 	f.StringVar(&c.Deployer, "deployer", "http://softleader.com.tw:5678", "deployer to deploy")
 	f.StringVar(&c.ServiceID, "service-id", "", "docker swarm service id to update")
 	f.BoolVar(&c.SkipSlack, "skip-slack", false, "skip slack webhook")
-	return cmd
+	f.BoolVar(&c.SkipPushCheck, "skip-push-check", false, "skip checking if the current commit has been pushed to GitHub")
+	f.StringVar(&c.NotesFile, "notes-file", "", "read release notes from file instead of generating from commits and pull requests")
+	f.BoolVar(&c.notesOnly, "notes-only", false, "print the release notes and exit without releasing")
+	f.StringVar(&c.Bump, "bump", "", "bump version from latest release when tag is not specified, one of: auto, major, minor, patch (default auto in interactive mode)")
+}
+
+// loadConfig 從當前目錄收集專案資訊, 再依序合併設定檔, 環境變數及 flags
+func (c *releaseCmd) loadConfig(f *pflag.FlagSet) error {
+	changed := changedFlags(f)
+	pwd, err := os.Getwd()
+	if err == nil {
+		if r, err := git.FindRemote(logrus.StandardLogger(), pwd, remote); err != nil {
+			logrus.Debugln(err)
+		} else {
+			useRemote(r)
+			c.SourceOwner, c.SourceRepo = r.Owner, r.Repo
+		}
+		c.Image.Name = c.SourceRepo
+		*c.Auth = *jib.GetAuth(logrus.StandardLogger(), pwd) // 保留原本的 pointer, 因為 flags 是綁定在上面的
+		if h, err := git.ResolveHead(logrus.StandardLogger(), pwd); err != nil {
+			logrus.Debugln(err)
+		} else {
+			c.head = h
+			c.SourceBranch = h.Branch
+		}
+	}
+	c.Image.Registry, c.Image.Namespace = imageRegistry, imageNamespace // 可以再被 command 下的 image 設定覆蓋
+	return mergeConfig(f, changed, pwd, "release", c)
 }
 
 func (c *releaseCmd) run() (err error) {
-	if _, err := github.CreateRelease(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.SourceBranch, c.Image.Tag); err != nil {
+	if c.notesOnly {
+		s, err := newSCM(c.SourceOwner, c.SourceRepo)
+		if err != nil {
+			return err
+		}
+		printNotes(releaseNotes(s, c.NotesFile, c.Image.Tag, commitish(c.head, c.SourceBranch)))
+		return nil
+	}
+	if err := checkHead(c.head, c.SourceOwner, c.SourceRepo, c.SourceBranch, c.SkipPushCheck); err != nil {
+		return err
+	}
+	if err := checkImageNotExists(c.Auth, c.Image, c.Force, "force"); err != nil {
+		return err
+	}
+	s, err := newSCM(c.SourceOwner, c.SourceRepo)
+	if err != nil {
+		return err
+	}
+	ref := commitish(c.head, c.SourceBranch)
+	if _, err := s.CreateRelease(ref, c.Image.Tag, releaseNotes(s, c.NotesFile, c.Image.Tag, ref)); err != nil {
 		return err
 	}
 
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"github.com/sirupsen/logrus"
 	"github.com/softleader/s2i/pkg/prompt"
 )
@@ -14,7 +15,7 @@ func releaseQuestions(c *releaseCmd) error {
 		return err
 	}
 
-	if err := prompt.AskServiceID(logrus.StandardLogger(), "s2i", metadata.String(), c.Deployer, c.Image.Name, c.ServiceID, c.promptSize, &c.ServiceID); err != nil {
+	if err := askReleaseServiceID(c); err != nil {
 		return err
 	}
 
@@ -53,3 +54,22 @@ func releaseQuestions(c *releaseCmd) error {
 	logrus.Println("That's try again!")
 	return releaseQuestions(c)
 }
+
+// askReleaseServiceID 問要更新的 service, release 是透過 Jenkins hook 更新 service, 因此只能選擇一個
+func askReleaseServiceID(c *releaseCmd) error {
+	var ids []string
+	if c.ServiceID != "" {
+		ids = append(ids, c.ServiceID)
+	}
+	if err := prompt.AskServiceID(newDeployer(c.Deployer), c.Image.Name, ids, c.promptSize, &ids); err != nil {
+		return err
+	}
+	if len(ids) > 1 {
+		return errors.New("release updates only one service through the Jenkins hook, use 'promote' to update multiple services")
+	}
+	c.ServiceID = ""
+	if len(ids) > 0 {
+		c.ServiceID = ids[0]
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/deployer"
+	"github.com/softleader/s2i/pkg/docker"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+)
+
+const pluginRollbackDesc = `將 SoftLeader Deployer 上的服務回復到上一次更新前的 image
+
+	$ s2i rollback --service-id SERVICE_ID
+
+s2i 每次更新服務前都會將服務當前的 image 記錄在 $HOME/.s2i/deployments/<service-id>.jsonl 中,
+'rollback' 會依照最後一次的記錄, 透過 Deployer 將服務更新回記錄中的 image;
+沒有記錄 (如服務不是由 s2i 更新的) 時, 會改用 docker 記錄的上一次設定中的 image
+
+也可以傳入 '--to' 指定要回復的 image
+
+	$ s2i rollback --service-id SERVICE_ID --to hub.softleader.com.tw/app:1.2.2
+
+傳入 '--wait' 會等到服務的所有 tasks 都以回復的 image 執行
+
+常用的 flag 可以寫在專案的 .s2i.yaml 或 $HOME/.s2i.yaml 的 'rollback' 下
+`
+
+type rollbackCmd struct {
+	Deployer  string
+	ServiceID string `yaml:"service-id"`
+	SkipSlack bool   `yaml:"skip-slack"`
+	rollout   `yaml:",inline"`
+	to        string
+}
+
+func newRollbackCmd() *cobra.Command {
+	c := &rollbackCmd{}
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "roll back a service on the deployer to its previous image",
+		Long:  pluginRollbackDesc,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.loadConfig(cmd.Flags()); err != nil {
+				return err
+			}
+			if c.ServiceID == "" {
+				return errors.New("--service-id is required")
+			}
+			return c.run()
+		},
+	}
+	c.flags(cmd.Flags())
+	f := cmd.Flags()
+	f.StringVar(&c.to, "to", "", "image to roll back to, e.g. hub.softleader.com.tw/app:1.2.2 (default: the previous image)")
+	return cmd
+}
+
+func (c *rollbackCmd) flags(f *pflag.FlagSet) {
+	f.StringVar(&c.Deployer, "deployer", "http://softleader.com.tw:5678", "deployer to deploy")
+	f.StringVar(&c.ServiceID, "service-id", "", "docker swarm service id to roll back")
+	f.BoolVar(&c.SkipSlack, "skip-slack", false, "skip slack webhook")
+	c.rollout.flags(f, false)
+}
+
+// loadConfig 依序合併設定檔, 環境變數及 flags
+func (c *rollbackCmd) loadConfig(f *pflag.FlagSet) error {
+	pwd, _ := os.Getwd()
+	return mergeConfig(f, changedFlags(f), pwd, "rollback", c)
+}
+
+func (c *rollbackCmd) run() error {
+	d := newDeployer(c.Deployer)
+	target, err := c.target(d)
+	if err != nil {
+		return err
+	}
+	image, err := docker.ParseImage(target)
+	if err != nil {
+		return err
+	}
+	logrus.Printf("Rolling back docker service id: %s to %s", c.ServiceID, image)
+	if err := updateService(c.Deployer, c.ServiceID, image, c.SkipSlack, c.rollout); err != nil {
+		return err
+	}
+	logrus.Printf("Service %s has been rolled back to %s", c.ServiceID, image)
+	return nil
+}
+
+// target 回傳要回復的 image, 依序為 '--to', s2i 最後一次更新前的 image, docker 記錄的上一次設定中的 image
+func (c *rollbackCmd) target(d *deployer.Client) (string, error) {
+	if c.to != "" {
+		return c.to, nil
+	}
+	s, err := d.InspectService(c.ServiceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect service %s: %s", c.ServiceID, err)
+	}
+	path, err := deployer.HistoryPath(c.ServiceID)
+	if err != nil {
+		return "", err
+	}
+	last, err := deployer.NewHistory(path).Last()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %s", path, err)
+	}
+	if last != nil && last.Previous != "" && last.Image == s.Image() {
+		return last.Previous, nil
+	}
+	if last != nil {
+		logrus.Debugf("service %s is running %s instead of %s recorded in %s", c.ServiceID, s.Image(), last.Image, path)
+	}
+	if previous := s.PreviousImage(); previous != "" {
+		return previous, nil
+	}
+	return "", fmt.Errorf("no previous image of service %s to roll back to, use '--to' to specify one", c.ServiceID)
+}
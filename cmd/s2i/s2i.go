@@ -3,11 +3,15 @@ package main
 import (
 	"fmt"
 	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/docker"
 	"github.com/softleader/s2i/pkg/formatter"
+	"github.com/softleader/s2i/pkg/git"
 	"github.com/softleader/s2i/pkg/release"
+	"github.com/softleader/s2i/pkg/scm"
 	"github.com/spf13/cobra"
 	"os"
 	"strconv"
+	"time"
 )
 
 var (
@@ -19,6 +23,24 @@ var (
 	offline, _ = strconv.ParseBool(os.Getenv("SL_OFFLINE"))
 	verbose, _ = strconv.ParseBool(os.Getenv("SL_VERBOSE"))
 	token      = os.Getenv("SL_TOKEN")
+	remote     = git.DefaultRemote
+	githubURL  = os.Getenv("SL_GITHUB_URL")
+
+	// 指定要使用的 SCM 及其網址, 空字串代表依照 git remote 判斷
+	scmProvider, scmURL string
+
+	// 從 git remote 收集到的 host, 用來判斷要使用的 SCM
+	remoteHost string
+
+	// image 要 push 到的 registry 及 namespace, registry 預設為 hub.softleader.com.tw
+	imageRegistry, imageNamespace string
+
+	// 呼叫 deployer 時的 bearer token 及每個 request 的 timeout
+	deployerToken   string
+	deployerTimeout = 30 * time.Second
+
+	// pre-release 各開發階段對應的 identifier, 可以寫在設定檔的 'stages' 下
+	stages = scm.DefaultStages()
 )
 
 func main() {
@@ -45,6 +67,9 @@ func newRootCmd(args []string) *cobra.Command {
 			if verbose {
 				logrus.SetLevel(logrus.DebugLevel)
 			}
+			if err := loadGlobalConfig(cmd.Flags()); err != nil {
+				return err
+			}
 			return nil
 		},
 	}
@@ -53,7 +78,11 @@ func newRootCmd(args []string) *cobra.Command {
 		newVersionCmd(),
 		newReleaseCmd(),
 		newPrereleaseCmd(),
+		newPromoteCmd(),
+		newRollbackCmd(),
+		newServiceCmd(),
 		neTagCmd(),
+		newConfigCmd(),
 	)
 
 	cmd.SilenceUsage = true
@@ -62,6 +91,14 @@ func newRootCmd(args []string) *cobra.Command {
 	f.BoolVar(&offline, "offline", offline, "work offline, Overrides $SL_OFFLINE")
 	f.BoolVarP(&verbose, "verbose", "v", verbose, "enable verbose output, Overrides $SL_VERBOSE")
 	f.StringVar(&token, "token", token, "github access token. Overrides $SL_TOKEN")
+	f.StringVar(&remote, "remote", remote, "name of the git remote to collect repo info from")
+	f.StringVar(&githubURL, "github-url", githubURL, "base url of GitHub Enterprise, e.g. https://github.example.com. Overrides $SL_GITHUB_URL")
+	f.StringVar(&scmProvider, "scm", "", "scm to manage tags and releases, one of: github, gitlab, gitea (default: detect from the git remote host)")
+	f.StringVar(&imageRegistry, "registry", docker.SoftleaderHub, "docker registry to push and manage images")
+	f.StringVar(&imageNamespace, "image-namespace", "", "namespace of image in the docker registry, e.g. team for team/app")
+	f.StringVar(&deployerToken, "deployer-token", "", "bearer token to call the deployer, leave blank if the deployer does not require one")
+	f.DurationVar(&deployerTimeout, "deployer-timeout", deployerTimeout, "timeout of each request to the deployer")
+	f.StringVar(&scmURL, "scm-url", "", "base url of the self-hosted scm, e.g. https://gitlab.example.com (default: https://<git remote host>)")
 	f.Parse(args)
 
 	return cmd
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/gitea"
+	"github.com/softleader/s2i/pkg/github"
+	"github.com/softleader/s2i/pkg/gitlab"
+	"github.com/softleader/s2i/pkg/scm"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	scmGitHub = "github"
+	scmGitLab = "gitlab"
+	scmGitea  = "gitea"
+)
+
+// scmGuessed 讓猜測的 SCM 只提醒一次
+var scmGuessed sync.Once
+
+// newSCM 依照 '--scm' 或 git remote 的 host 建立對應的 SCM client
+func newSCM(owner, repo string) (scm.SCM, error) {
+	provider, baseURL, guessed := resolveSCM()
+	if guessed {
+		scmGuessed.Do(func() { // 寫到 stderr, 以免混入 '-o json' 等給 script 用的輸出
+			fmt.Fprintf(os.Stderr, "using %s (%s) detected from the git remote host %s to manage tags and releases, pass '--scm' if it is wrong\n", provider, baseURL, remoteHost)
+		})
+	}
+	logrus.Debugf("using %s (%s) to manage tags and releases of %s/%s", provider, baseURL, owner, repo)
+	switch provider {
+	case scmGitHub:
+		return github.NewClient(logrus.StandardLogger(), baseURL, token, owner, repo)
+	case scmGitLab:
+		if baseURL == "" {
+			return nil, fmt.Errorf("base url of GitLab is required, please pass it by '--scm-url'")
+		}
+		return gitlab.NewClient(logrus.StandardLogger(), baseURL, token, owner, repo), nil
+	case scmGitea:
+		if baseURL == "" {
+			return nil, fmt.Errorf("base url of Gitea is required, please pass it by '--scm-url'")
+		}
+		return gitea.NewClient(logrus.StandardLogger(), baseURL, token, owner, repo), nil
+	}
+	return nil, fmt.Errorf("unsupported scm %q, must be one of: %s, %s, %s", provider, scmGitHub, scmGitLab, scmGitea)
+}
+
+// resolveSCM 決定要使用的 SCM 及其網址, 依序為:
+//
+//  1. 明確指定的 '--scm' (或設定檔中的 'scm')
+//  2. '--github-url' 的 host 與 git remote 相同 (或沒有 remote) 時為 GitHub Enterprise
+//  3. git remote 為 github.com 或沒有 remote 時為 GitHub
+//  4. git remote host 中有一段為 gitlab 或 gitea 時, e.g. gitlab.example.com
+//  5. 其餘無法判斷的 host 就當作是 GitHub Enterprise
+//
+// guessed 代表 provider 是從 remote host 猜測的, 可能猜錯
+func resolveSCM() (provider, baseURL string, guessed bool) {
+	provider, baseURL = strings.ToLower(scmProvider), scmURL
+	if provider == "" {
+		switch label := hostLabel(remoteHost, scmGitLab, scmGitea); {
+		case githubURL != "" && (remoteHost == "" || sameHost(githubURL, remoteHost)):
+			provider = scmGitHub
+		case remoteHost == "" || remoteHost == github.Host:
+			provider = scmGitHub
+		case label != "":
+			provider, guessed = label, true
+		default:
+			provider, guessed = scmGitHub, true
+		}
+	}
+	if baseURL == "" && provider == scmGitHub && (remoteHost == "" || sameHost(githubURL, remoteHost)) {
+		baseURL = githubURL
+	}
+	if baseURL == "" && remoteHost != "" && remoteHost != github.Host {
+		baseURL = "https://" + remoteHost + "/"
+	}
+	return
+}
+
+// hostLabel 回傳 host 中以 '.' 分隔的某一段完全等於 labels 之一時的 label, e.g. gitlab.example.com 為 gitlab, mygitlab.example.com 則不符合
+func hostLabel(host string, labels ...string) string {
+	for _, part := range strings.Split(strings.ToLower(host), ".") {
+		for _, label := range labels {
+			if part == label {
+				return label
+			}
+		}
+	}
+	return ""
+}
+
+// sameHost 判斷 rawurl 與 host 是否為同一台主機, 只比較 hostname 而忽略 port, e.g. https://ghe:8443/api/v3 與 ghe
+func sameHost(rawurl, host string) bool {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.EqualFold(u.Hostname(), host)
+}
+
+// nextVersion 依照 bump 找出下一版的 tag
+func nextVersion(owner, repo string, bump scm.Bump, head string) (string, error) {
+	s, err := newSCM(owner, repo)
+	if err != nil {
+		return "", err
+	}
+	next, err := scm.FindNextReleaseVersion(logrus.StandardLogger(), s, bump, head)
+	if err != nil {
+		return "", fmt.Errorf("failed to bump version from the latest release: %s", err)
+	}
+	return next, nil
+}
+
+// bumpVersion 在沒有指定 tag 時依照 '--bump' 找出下一版的 tag,
+// interactive 時找不到只會提醒並留給使用者輸入, 否則回傳 error
+func bumpVersion(owner, repo, bump, head string, interactive bool) (string, error) {
+	if bump == "" {
+		if !interactive {
+			return "", errors.New(`accepts 1 arg(s), received 0`)
+		}
+		bump = string(scm.BumpAuto)
+	}
+	b, err := scm.ParseBump(bump)
+	if err != nil {
+		return "", err
+	}
+	next, err := nextVersion(owner, repo, b, head)
+	if err != nil && interactive {
+		logrus.Warnln(err)
+		return "", nil
+	}
+	return next, err
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestResolveSCM(t *testing.T) {
+	defer func(provider, url, gh, host string) {
+		scmProvider, scmURL, githubURL, remoteHost = provider, url, gh, host
+	}(scmProvider, scmURL, githubURL, remoteHost)
+	tests := []struct {
+		githubURL, remoteHost string
+		provider, baseURL     string
+		guessed               bool
+	}{
+		{"", "github.com", scmGitHub, "", false},
+		{"https://ghe:8443/api/v3", "ghe", scmGitHub, "https://ghe:8443/api/v3", false},
+		{"https://ghe/", "ghe:8443", scmGitHub, "https://ghe/", false},
+		{"https://ghe/", "gitlab.example.com", scmGitLab, "https://gitlab.example.com/", true},
+		{"", "git.example.com", scmGitHub, "https://git.example.com/", true},
+	}
+	for _, tt := range tests {
+		scmProvider, scmURL, githubURL, remoteHost = "", "", tt.githubURL, tt.remoteHost
+		provider, baseURL, guessed := resolveSCM()
+		if provider != tt.provider || baseURL != tt.baseURL || guessed != tt.guessed {
+			t.Errorf("%s, %s: expected %s (%s) guessed %v, but got %s (%s) guessed %v",
+				tt.githubURL, tt.remoteHost, tt.provider, tt.baseURL, tt.guessed, provider, baseURL, guessed)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+)
+
+const pluginServiceDesc = `查詢 SoftLeader Deployer 上的服務, 方便確認各服務目前執行的版本
+`
+
+func newServiceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "service",
+		Short: "inspect services on the deployer",
+		Long:  pluginServiceDesc,
+	}
+	cmd.AddCommand(
+		newServiceListCmd(),
+		newServiceInspectCmd(),
+	)
+	return cmd
+}
+
+// loadServiceConfig 依序合併設定檔, 環境變數及 flags, service 下的 commands 共用 'service' section
+func loadServiceConfig(f *pflag.FlagSet, c interface{}) error {
+	pwd, _ := os.Getwd()
+	return mergeConfig(f, changedFlags(f), pwd, "service", c)
+}
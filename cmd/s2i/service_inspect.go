@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/docker"
+	"github.com/softleader/s2i/pkg/git"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+const pluginServiceInspectDesc = `查看 SoftLeader Deployer 上服務的 image, replicas, ports, 並比較執行中的版本與最新的 release
+
+	$ s2i service inspect SERVICE_ID
+
+最新的 release 預設從與 image 同名的 repo 查詢, 可傳入 '--source-owner', '--source-repo' 調整;
+當前目錄的 git remote 只有在其 repo 與 image 同名或有傳入 '--source-repo' 時才會用來決定 owner,
+無法決定 owner 時狀態為 unknown
+
+傳入 '--output' 可以調整輸出格式, 可以是 table (預設), json, yaml 或 name (只印出服務名稱)
+
+	$ s2i service inspect SERVICE_ID -o json
+`
+
+const (
+	serviceUpToDate = "up to date"
+	serviceBehind   = "behind"
+	serviceAhead    = "ahead"
+	serviceUnknown  = "unknown"
+)
+
+type serviceInspectCmd struct {
+	Deployer    string
+	SourceOwner string `yaml:"-"`
+	SourceRepo  string `yaml:"-"`
+	Output      string `yaml:"-"`
+	ID          string `yaml:"-"`
+	remote      *git.Remote
+}
+
+// serviceInfo 是 'service inspect' 印出的服務資訊
+type serviceInfo struct {
+	ID            string `json:"id" yaml:"id"`
+	Name          string `json:"name" yaml:"name"`
+	Image         string `json:"image" yaml:"image"`
+	Replicas      string `json:"replicas" yaml:"replicas"`
+	Ports         string `json:"ports" yaml:"ports"`
+	Tag           string `json:"tag" yaml:"tag"`
+	LatestRelease string `json:"latest_release" yaml:"latest-release"`
+	Status        string `json:"status" yaml:"status"` // 執行中的版本與最新 release 相比為 up to date, behind, ahead 或 unknown
+}
+
+func newServiceInspectCmd() *cobra.Command {
+	c := &serviceInspectCmd{}
+	cmd := &cobra.Command{
+		Use:   "inspect <SERVICE_ID>",
+		Short: "show a service on the deployer and compare its tag to the latest release",
+		Long:  pluginServiceInspectDesc,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pwd, err := os.Getwd(); err == nil {
+				if r, err := git.FindRemote(logrus.StandardLogger(), pwd, remote); err != nil {
+					logrus.Debugln(err)
+				} else {
+					c.remote = r
+				}
+			}
+			if err := loadServiceConfig(cmd.Flags(), c); err != nil {
+				return err
+			}
+			if err := checkOutput(c.Output); err != nil {
+				return err
+			}
+			c.ID = args[0]
+			return c.run(cmd.OutOrStdout())
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&c.Deployer, "deployer", "http://softleader.com.tw:5678", "deployer to query")
+	f.StringVar(&c.SourceOwner, "source-owner", "", "name of the owner (user or org) of the repo to find the latest release (default: the owner of the git remote if its repo matches)")
+	f.StringVar(&c.SourceRepo, "source-repo", "", "name of repo to find the latest release (default: the name of image)")
+	f.StringVarP(&c.Output, "output", "o", outputTable, "output format, one of: table, json, yaml, name")
+	return cmd
+}
+
+func (c *serviceInspectCmd) run(out io.Writer) error {
+	s, err := newDeployer(c.Deployer).InspectService(c.ID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect service %s: %s", c.ID, err)
+	}
+	info := &serviceInfo{
+		ID:       s.ID,
+		Name:     s.Name(),
+		Image:    s.Image(),
+		Replicas: s.Replicas(),
+		Ports:    s.Ports(),
+		Status:   serviceUnknown,
+	}
+	image, err := docker.ParseImage(s.Image())
+	if err != nil {
+		logrus.Debugln(err)
+	} else {
+		info.Tag = image.Tag
+		if err := c.compare(info, image); err != nil {
+			warn := logrus.Warnf
+			if c.Output != outputTable { // 其他格式是給 script 用的, 不混入警告訊息
+				warn = logrus.Debugf
+			}
+			warn("failed to compare %s to the latest release: %s", info.Tag, err)
+		}
+	}
+	return printServiceInfo(out, c.Output, info)
+}
+
+// compare 查詢 repo 最新的 release, 並與服務執行中的 tag 比較
+func (c *serviceInspectCmd) compare(info *serviceInfo, image *docker.Image) error {
+	owner, repo := c.SourceOwner, c.SourceRepo
+	if repo == "" {
+		repo = image.Name
+	}
+	// 當前目錄不一定是此服務的 repo, 只有 repo 相符或有指定 repo 時才使用其 remote
+	if r := c.remote; r != nil && (c.SourceRepo != "" || r.Repo == repo) {
+		useRemote(r)
+		if owner == "" {
+			owner = r.Owner
+		}
+	} else if r != nil {
+		logrus.Debugf("ignoring the git remote %s/%s since it does not match image %s", r.Owner, r.Repo, image.Name)
+	}
+	if owner == "" {
+		return fmt.Errorf("owner of %s is unknown, please pass it by '--source-owner'", repo)
+	}
+	s, err := newSCM(owner, repo)
+	if err != nil {
+		return err
+	}
+	latest, err := s.LatestRelease()
+	if err == scm.ErrNotFound {
+		return fmt.Errorf("no release found on %s/%s", owner, repo)
+	}
+	if err != nil {
+		return err
+	}
+	info.LatestRelease = latest.TagName
+	cmp, err := scm.CompareVersions(info.Tag, latest.TagName)
+	if err != nil {
+		return err
+	}
+	switch {
+	case cmp < 0:
+		info.Status = serviceBehind
+	case cmp > 0:
+		info.Status = serviceAhead
+	default:
+		info.Status = serviceUpToDate
+	}
+	return nil
+}
+
+// printServiceInfo 依照 output 格式將 info 印到 w
+func printServiceInfo(w io.Writer, output string, info *serviceInfo) error {
+	switch output {
+	case outputJSON, outputYAML:
+		return printObject(w, output, info)
+	case outputName:
+		_, err := fmt.Fprintln(w, info.Name)
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID:\t%s\n", info.ID)
+	fmt.Fprintf(tw, "Name:\t%s\n", info.Name)
+	fmt.Fprintf(tw, "Image:\t%s\n", info.Image)
+	fmt.Fprintf(tw, "Replicas:\t%s\n", info.Replicas)
+	fmt.Fprintf(tw, "Ports:\t%s\n", info.Ports)
+	fmt.Fprintf(tw, "Tag:\t%s\n", info.Tag)
+	fmt.Fprintf(tw, "Latest release:\t%s\n", info.LatestRelease)
+	fmt.Fprintf(tw, "Status:\t%s\n", info.Status)
+	return tw.Flush()
+}
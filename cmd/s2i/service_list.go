@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+)
+
+const pluginServiceListDesc = `列出 SoftLeader Deployer 上的服務
+
+	$ s2i service list
+
+傳入 '--app' 只列出 label 為 app=<APP> 的服務
+
+	$ s2i service list --app my-app
+
+傳入 '--output' 可以調整輸出格式, 可以是 table (預設), json, yaml 或 name (只列出服務名稱), 方便與其他 script 整合
+
+	$ s2i service list -o json
+`
+
+type serviceListCmd struct {
+	Deployer string
+	App      string `yaml:"-"`
+	Output   string `yaml:"-"`
+}
+
+func newServiceListCmd() *cobra.Command {
+	c := &serviceListCmd{}
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "list services on the deployer",
+		Long:    pluginServiceListDesc,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadServiceConfig(cmd.Flags(), c); err != nil {
+				return err
+			}
+			if err := checkOutput(c.Output); err != nil {
+				return err
+			}
+			return c.run(cmd.OutOrStdout())
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&c.Deployer, "deployer", "http://softleader.com.tw:5678", "deployer to query")
+	f.StringVar(&c.App, "app", "", "only list services with the label app=<APP>")
+	f.StringVarP(&c.Output, "output", "o", outputTable, "output format, one of: table, json, yaml, name")
+	return cmd
+}
+
+func (c *serviceListCmd) run(out io.Writer) error {
+	params := make(map[string]string)
+	if c.App != "" {
+		params["label"] = fmt.Sprintf("app=%s", c.App)
+	}
+	services, err := newDeployer(c.Deployer).FilterService(params)
+	if err != nil {
+		return err
+	}
+	return printServices(out, c.Output, services)
+}
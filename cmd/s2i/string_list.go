@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/csv"
+	"github.com/spf13/pflag"
+	"strings"
+)
+
+// stringList 是可重複傳入的 flag, 每次也可以傳入以 ',' 分隔的多個值, 設定檔中可以寫成單一個值或 list
+type stringList []string
+
+// UnmarshalYAML 讓設定檔可以寫成單一個值, e.g. 'service-id: xxxxx', 或 list
+func (l *stringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*l = nil
+		if s != "" {
+			*l = stringList{s}
+		}
+		return nil
+	}
+	var ss []string
+	if err := unmarshal(&ss); err != nil {
+		return err
+	}
+	*l = ss
+	return nil
+}
+
+func stringListVar(f *pflag.FlagSet, p *stringList, name, usage string) {
+	f.Var(&stringListValue{value: p}, name, usage)
+}
+
+// stringListValue 實作 pflag.Value, 第一次 Set 會取代原本的值 (如設定檔中的值), 之後則是累加
+// mergeConfig 會以 String() 的格式 (e.g. [a,b], 沒有值時為空字串) 重新套用 command line 傳入的值, 此時也是取代
+type stringListValue struct {
+	value   *stringList
+	changed bool
+}
+
+func (v *stringListValue) Set(s string) error {
+	replace := s == "" || strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]")
+	if replace {
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	}
+	var values []string
+	if s != "" {
+		r, err := csv.NewReader(strings.NewReader(s)).Read()
+		if err != nil {
+			return err
+		}
+		for _, value := range r {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	if replace || !v.changed {
+		*v.value = values
+	} else {
+		*v.value = append(*v.value, values...)
+	}
+	v.changed = true
+	return nil
+}
+
+func (v *stringListValue) Type() string {
+	return "strings"
+}
+
+func (v *stringListValue) String() string {
+	if len(*v.value) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(*v.value, ",") + "]"
+}
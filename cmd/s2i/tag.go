@@ -1,6 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/journal"
+	"github.com/softleader/s2i/pkg/prompt"
+	"github.com/softleader/s2i/pkg/scm"
 	"github.com/spf13/cobra"
 )
 
@@ -15,7 +20,50 @@ func neTagCmd() *cobra.Command {
 	}
 	cmd.AddCommand(
 		newTagListCmd(),
+		newTagCreateCmd(),
+		newTagMoveCmd(),
 		newTagDeleteCmd(),
+		newTagPruneCmd(),
+		newTagRestoreCmd(),
 	)
 	return cmd
 }
+
+// deleteTags 列出要刪除的 tags 並確認後, 以 parallel 個 worker 刪除 tag 及其 release, 並記錄到 journal 中以便日後 restore
+func deleteTags(s scm.SCM, owner, repo string, tags []string, parallel int, dryRun, yes bool) error {
+	if len(tags) == 0 {
+		logrus.Infof("no tags matched")
+		return nil
+	}
+	logrus.Infof("%d tag(s) to delete:", len(tags))
+	for _, tag := range tags {
+		logrus.Infof("  %s", tag)
+	}
+	if !dryRun && !yes {
+		if ok, err := confirm(fmt.Sprintf("Delete %d tag(s) and their releases?", len(tags))); err != nil || !ok {
+			return err
+		}
+	}
+	path, err := journal.Path(owner, repo)
+	if err != nil {
+		return err
+	}
+	j := journal.New(path)
+	summary := scm.DeleteReleasesAndTags(logrus.StandardLogger(), journal.Wrap(s, j), tags, parallel, dryRun)
+	summary.Print(logrus.StandardLogger(), dryRun)
+	if !dryRun && len(summary.Deleted) > 0 {
+		logrus.Infof("deleted tags are recorded in %s, run 'tag restore' to restore them", j.Path())
+	}
+	return summary.Err()
+}
+
+// confirm 詢問使用者是否繼續, 回答 no 時會提醒已中止
+func confirm(question string) (ok bool, err error) {
+	if err = prompt.AskYesNo(question, "n", &ok); err != nil {
+		return
+	}
+	if !ok {
+		logrus.Infof("aborted")
+	}
+	return
+}
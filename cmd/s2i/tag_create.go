@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/git"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"time"
+)
+
+const pluginTagCreateDesc = `建立 tag, 但不建立 release
+
+REF 可以是 branch 或 commit SHA, 不傳入時會建立在當前的 commit 上,
+此時 s2i 會先確認該 commit 已經 push 到 GitHub, 尚未 push 時將會中止, 可傳入 '--skip-push-check' 略過此檢查
+
+	$ s2i tag create TAG
+	$ s2i tag create TAG REF
+
+TAG 必須是有效的 semver2 版號 (允許 'v' 開頭), 預設建立 lightweight tag, 傳入 '--message' 則建立 annotated tag
+
+	$ s2i tag create 1.2.3 master -m "hotfix for 1.2.2"
+
+傳入 '--sign' 會依照 git config 中的 'user.signingkey' 及 'gpg.program' 以 gpg 簽署 annotated tag, 也可以傳入 '--local-user' 指定 key,
+簽署的內容與 'git tag -s' 相同, 因此 fetch 回來後可以透過 'git tag -v' 驗證 (GitLab 及 Gitea 的 api 不支援簽署)
+
+	$ s2i tag create 1.2.3 -m "release 1.2.3" --sign
+
+s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調整:
+
+	- git 資訊: '--source-owner', '--source-repo', 預設從名為 origin 的 remote 收集, 可傳入 '--remote' 指定
+`
+
+type tagCreateCmd struct {
+	Tag           string
+	Ref           string
+	SourceOwner   string `yaml:"source-owner"`
+	SourceRepo    string `yaml:"source-repo"`
+	SkipPushCheck bool   `yaml:"skip-push-check"`
+	tagMessage    `yaml:",inline"`
+	head          *git.Head
+}
+
+func newTagCreateCmd() *cobra.Command {
+	c := &tagCreateCmd{}
+	cmd := &cobra.Command{
+		Use:   "create <TAG> [REF]",
+		Short: "create a tag without a release",
+		Long:  pluginTagCreateDesc,
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pwd, err := os.Getwd(); err == nil {
+				if len(c.SourceOwner) == 0 || len(c.SourceRepo) == 0 {
+					r, err := git.FindRemote(logrus.StandardLogger(), pwd, remote)
+					if err != nil {
+						return err
+					}
+					useRemote(r)
+					if len(c.SourceOwner) == 0 {
+						c.SourceOwner = r.Owner
+					}
+					if len(c.SourceRepo) == 0 {
+						c.SourceRepo = r.Repo
+					}
+				}
+				if h, err := git.ResolveHead(logrus.StandardLogger(), pwd); err != nil {
+					logrus.Debugln(err)
+				} else {
+					c.head = h
+				}
+			}
+			c.Tag = args[0]
+			if len(args) > 1 {
+				c.Ref = args[1]
+			}
+			if err := scm.CheckVersion(c.Tag); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&c.SourceOwner, "source-owner", c.SourceOwner, "name of the owner (user or org) of the repo to create tag")
+	f.StringVar(&c.SourceRepo, "source-repo", c.SourceRepo, "name of repo to create tag")
+	f.BoolVar(&c.SkipPushCheck, "skip-push-check", false, "skip checking if the current commit has been pushed to GitHub")
+	c.tagMessage.flags(f)
+	return cmd
+}
+
+func (c *tagCreateCmd) run() error {
+	ref := c.Ref
+	if ref == "" {
+		if c.head == nil || c.head.Commit == "" {
+			return fmt.Errorf("requires REF since the current directory is not a git repository")
+		}
+		if err := checkHead(c.head, c.SourceOwner, c.SourceRepo, c.head.Branch, c.SkipPushCheck); err != nil {
+			return err
+		}
+		ref = c.head.Commit
+	}
+	opts, err := c.tagMessage.options()
+	if err != nil {
+		return err
+	}
+	s, err := newSCM(c.SourceOwner, c.SourceRepo)
+	if err != nil {
+		return err
+	}
+	if err := s.CreateTag(ref, c.Tag, opts); err != nil {
+		return err
+	}
+	logrus.Infof("'%s' has been created on %s", c.Tag, ref)
+	return nil
+}
+
+// tagMessage 是建立 annotated tag 的 flag, 'tag create' 及 'tag move' 共用
+type tagMessage struct {
+	Message   string
+	Sign      bool
+	LocalUser string `yaml:"local-user"`
+}
+
+func (t *tagMessage) flags(f *pflag.FlagSet) {
+	f.StringVarP(&t.Message, "message", "m", "", "create an annotated tag with the message")
+	f.BoolVar(&t.Sign, "sign", false, "make a GPG-signed annotated tag, using the signing key of git config")
+	f.StringVarP(&t.LocalUser, "local-user", "u", "", "make a GPG-signed annotated tag using the given key")
+}
+
+// options 回傳建立 tag 的選項, 會從當前目錄的 git config 讀取 tagger 及簽署的設定
+func (t *tagMessage) options() (*scm.TagOptions, error) {
+	sign := t.Sign || t.LocalUser != ""
+	if t.Message == "" {
+		if sign {
+			return nil, fmt.Errorf("requires '--message' to make a signed tag")
+		}
+		return nil, nil
+	}
+	opts := &scm.TagOptions{Message: t.Message}
+	config, err := loadGitConfig()
+	if err != nil {
+		if sign {
+			return nil, err
+		}
+		logrus.Debugln(err)
+		return opts, nil
+	}
+	if name, email := config.User(); name != "" && email != "" {
+		opts.Tagger = &scm.Tagger{Name: name, Email: email, Date: time.Now()}
+	}
+	if sign {
+		if opts.Tagger == nil {
+			return nil, fmt.Errorf("requires 'user.name' and 'user.email' in git config to make a signed tag")
+		}
+		opts.Sign = git.NewSigner(logrus.StandardLogger(), config, t.LocalUser).Sign
+	}
+	return opts, nil
+}
+
+// loadGitConfig 載入當前目錄的 git config, 不在 git repo 中時只載入 global 的設定
+func loadGitConfig() (*git.Config, error) {
+	var gitDir string
+	if pwd, err := os.Getwd(); err == nil {
+		if dir, err := git.FindGitDir(pwd); err == nil {
+			gitDir = dir
+		}
+	}
+	return git.LoadConfig(logrus.StandardLogger(), gitDir)
+}
@@ -3,7 +3,8 @@ package main
 import (
 	"fmt"
 	"github.com/sirupsen/logrus"
-	"github.com/softleader/s2i/pkg/github"
+	"github.com/softleader/s2i/pkg/git"
+	"github.com/softleader/s2i/pkg/scm"
 	"github.com/spf13/cobra"
 	"os"
 )
@@ -17,7 +18,7 @@ const pluginTagDeleteDesc = `刪除 tag 及其 release
 
 s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調整:
 
-	- git 資訊: '--source-owner', '--source-repo'
+	- git 資訊: '--source-owner', '--source-repo', 預設從名為 origin 的 remote 收集, 可傳入 '--remote' 指定
 
 傳入 '--regex' 將以 regular expression 方式模糊過濾 tag, 並刪除之
 
@@ -32,9 +33,48 @@ s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調
 
 	$ slctl s2i tag delete RANGE... -s --dry-run
 
-模糊過濾 flag ('-r' 或 '-s' 等) 使用上請注意: 
+刪除前會先列出所有要刪除的 tag 並要求確認, 傳入 '--yes' 可略過確認
+每個被刪除的 tag 及 release 都會記錄在 $HOME/.s2i/journal 中, 可以透過 'tag restore' 重新建立
+
+	$ slctl s2i tag restore TAG..
+
+傳入 '--delete-image' 會在刪除 tag 後, 一併刪除 registry 上同名 tag 的 image, image 名稱預設為 repo 名稱, 可傳入 '--image' 調整
+為了避免誤刪, image 與其他 tag 共用相同的 manifest 時 (如 promote 後的正式版) 將不會刪除, 被刪除的 image 也無法透過 'tag restore' 重新建立
+
+	$ slctl s2i tag delete "<2.0.0" -s --stage alpha --delete-image
+
+傳入 '--parallel' 可以同時刪除多個 tag, 遇到 rate limit 時會自動等待後重試
+任何一個 tag 刪除失敗都不會中斷其他 tag 的刪除, 最後會列出刪除, 略過 (tag 及 release 都不存在) 及失敗的數量
+
+	$ slctl s2i tag delete "<2.5.x" -s --parallel 4
+
+傳入 '--glob' 將以 glob pattern 方式模糊過濾 tag, 並刪除之
+
+	$ slctl s2i tag delete "1.2.*" -g
+
+可以再組合以下過濾條件, 所有條件之間都是 AND:
+
+	- '--exclude': 排除符合的 tag, 以與 TAG 相同的方式判斷 (可傳入多個)
+	- '--and-regex', '--and-semver', '--and-glob': tag 必須同時符合該 regex, range 或 pattern (可傳入多個, 符合其一即可)
+	- '--stage': 只包含該 stage 的 pre-release
+	- '--author', '--exclude-author': 只包含或排除該人員發佈的 release (可傳入多個)
+	- '--older-than': 只包含發佈超過 N 天的 release
+
+以 release 資訊 ('--author', '--exclude-author', '--older-than') 過濾時, 沒有 release 的 tag (tag only) 只會被 '--exclude-author' 保留
+
+	# 刪除 2.0 以下的 alpha, 但保留 matt 發佈的
+	$ slctl s2i tag delete "<2.0.0" -s --stage alpha --exclude-author matt
+
+	# 刪除 2.0 以下且以 -0 結尾的 tag, 但排除 1.2.x
+	$ slctl s2i tag delete "<2.0.0" -s --and-regex "-0$" --exclude "1.2.x"
+
+傳入過濾條件時 TAG 可以不傳入, 代表從所有的 tag 中過濾
+
+	$ slctl s2i tag delete --stage alpha --older-than 30
+
+模糊過濾 flag ('-r', '-s', '-g' 或其他過濾條件) 使用上請注意: 
 - 將會 scan 所有 GitHub 上所有的 tag, 效能自然會比完全比對 tag 來得差
-- 判斷先後順序依序為: '-r', '-s'
+- 判斷先後順序依序為: '-r', '-s', '-g'
 
 Example:
 
@@ -55,12 +95,16 @@ Example:
 `
 
 type tagDeleteCmd struct {
-	Tags                      []string
-	SourceOwner               string `yaml:"source-owner"`
-	SourceRepo                string `yaml:"source-repo"`
-	DryRun                    bool   `yaml:"dry-run"`
-	Interactive               bool
-	github.TagMatcherStrategy `yaml:"tag-matcher-strategy"`
+	Tags                   []string
+	SourceOwner            string `yaml:"source-owner"`
+	SourceRepo             string `yaml:"source-repo"`
+	DryRun                 bool   `yaml:"dry-run"`
+	Parallel               int
+	Yes                    bool
+	Interactive            bool
+	scm.TagMatcherStrategy `yaml:"tag-matcher-strategy"`
+	tagFilter              `yaml:",inline"`
+	tagImage               `yaml:",inline"`
 }
 
 func newTagDeleteCmd() *cobra.Command {
@@ -73,15 +117,16 @@ func newTagDeleteCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(c.SourceOwner) == 0 || len(c.SourceRepo) == 0 {
 				if pwd, err := os.Getwd(); err == nil {
-					t, owner, repo := github.Remote(logrus.StandardLogger(), pwd)
-					if len(c.SourceOwner) != 0 { // 代表此 repo 是用指定 token clone 的, 因此換掉這次 global 的 token
-						token = t
+					r, err := git.FindRemote(logrus.StandardLogger(), pwd, remote)
+					if err != nil {
+						return err
 					}
+					useRemote(r)
 					if len(c.SourceOwner) == 0 {
-						c.SourceOwner = owner
+						c.SourceOwner = r.Owner
 					}
 					if len(c.SourceRepo) == 0 {
-						c.SourceRepo = repo
+						c.SourceRepo = r.Repo
 					}
 				}
 			}
@@ -91,7 +136,7 @@ func newTagDeleteCmd() *cobra.Command {
 					return err
 				}
 			}
-			if len := len(c.Tags); len == 0 {
+			if len := len(c.Tags); len == 0 && c.tagFilter.isEmpty() {
 				return fmt.Errorf("requires at least 1 arg(s), only received %v", len)
 			}
 			return c.run()
@@ -103,25 +148,30 @@ func newTagDeleteCmd() *cobra.Command {
 	f.StringVar(&c.SourceOwner, "source-owner", c.SourceOwner, "name of the owner (user or org) of the repo to delete tag")
 	f.StringVar(&c.SourceRepo, "source-repo", c.SourceRepo, "name of repo to delete tag")
 	f.BoolVar(&c.DryRun, "dry-run", false, "simulate tag deletion \"for real\"")
+	f.IntVar(&c.Parallel, "parallel", 1, "number of tags to delete concurrently")
+	f.BoolVarP(&c.Yes, "yes", "y", false, "delete without confirmation")
 	f.BoolVarP(&c.Regex, "regex", "r", false, "matches tag by regex (bad performance warning, it'll scan over all tags of the repo)")
 	f.BoolVarP(&c.SemVer, "semver", "s", false, "matches tag by semantic versioning (bad performance warning, it'll scan over all tags of the repo)")
+	f.BoolVarP(&c.Glob, "glob", "g", false, "matches tag by glob pattern, e.g. 1.2.* (bad performance warning, it'll scan over all tags of the repo)")
+	c.tagFilter.flags(f, true)
+	c.tagImage.flags(f)
 	return cmd
 }
 
 func (c *tagDeleteCmd) run() error {
-	if c.Regex {
-		matcher, err := github.NewRegexMatcher(c.Tags)
-		if err != nil {
-			return err
-		}
-		return github.DeleteMatchesReleasesAndTags(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher, c.DryRun)
+	s, err := newSCM(c.SourceOwner, c.SourceRepo)
+	if err != nil {
+		return err
+	}
+	matcher, err := c.tagFilter.matcher(s, &c.TagMatcherStrategy, c.Tags)
+	if err != nil {
+		return err
 	}
-	if c.SemVer {
-		matcher, err := github.NewSemVerMatcher(c.Tags)
-		if err != nil {
+	tags := c.Tags
+	if matcher != nil {
+		if tags, err = scm.FindMatchedTags(logrus.StandardLogger(), s, matcher); err != nil {
 			return err
 		}
-		return github.DeleteMatchesReleasesAndTags(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher, c.DryRun)
 	}
-	return github.DeleteReleasesAndTags(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.Tags, c.DryRun)
+	return deleteTags(c.tagImage.wrap(s, c.SourceRepo), c.SourceOwner, c.SourceRepo, tags, c.Parallel, c.DryRun, c.Yes)
 }
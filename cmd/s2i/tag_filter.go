@@ -0,0 +1,106 @@
+package main
+
+import (
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/spf13/pflag"
+	"time"
+)
+
+// tagFilter 是 tag 相關 command 共用的過濾條件, 所有條件之間都是 AND
+type tagFilter struct {
+	Exclude        []string
+	AndRegex       []string `yaml:"and-regex"`
+	AndSemVer      []string `yaml:"and-semver"`
+	AndGlob        []string `yaml:"and-glob"`
+	Stage          string
+	Authors        []string `yaml:"authors"`
+	ExcludeAuthors []string `yaml:"exclude-authors"`
+	OlderThan      int      `yaml:"older-than"`
+}
+
+// flags 註冊過濾條件的 flag, age 為 false 時不註冊 '--older-than' (如 prune 已有自己的保留規則)
+func (t *tagFilter) flags(f *pflag.FlagSet, age bool) {
+	f.StringArrayVar(&t.Exclude, "exclude", nil, "exclude tags matching the expression, interpreted by the same matcher strategy as TAG (repeatable)")
+	f.StringArrayVar(&t.AndRegex, "and-regex", nil, "tags must also match the regex (repeatable, any of them)")
+	f.StringArrayVar(&t.AndSemVer, "and-semver", nil, "tags must also match the semantic versioning range (repeatable, any of them)")
+	f.StringArrayVar(&t.AndGlob, "and-glob", nil, "tags must also match the glob pattern (repeatable, any of them)")
+	f.StringVar(&t.Stage, "stage", "", "only pre-release tags of the stage, one of: alpha, beta, rc, or any pre-release identifier")
+	f.StringArrayVar(&t.Authors, "author", nil, "only tags whose release was published by the author (repeatable)")
+	f.StringArrayVar(&t.ExcludeAuthors, "exclude-author", nil, "exclude tags whose release was published by the author (repeatable)")
+	if age {
+		f.IntVar(&t.OlderThan, "older-than", 0, "only tags whose release was published more than N days ago, 0 for no limit")
+	}
+}
+
+// isEmpty 判斷是否沒有任何過濾條件
+func (t *tagFilter) isEmpty() bool {
+	return len(t.Exclude) == 0 && len(t.AndRegex) == 0 && len(t.AndSemVer) == 0 && len(t.AndGlob) == 0 &&
+		t.Stage == "" && len(t.Authors) == 0 && len(t.ExcludeAuthors) == 0 && t.OlderThan <= 0
+}
+
+// byRelease 判斷是否有需要 release 資訊 (發佈人員, 發佈時間) 的過濾條件
+func (t *tagFilter) byRelease() bool {
+	return len(t.Authors) > 0 || len(t.ExcludeAuthors) > 0 || t.OlderThan > 0
+}
+
+// matcher 依照 strategy 及過濾條件組成 TagMatcher, 回傳 nil 代表只需完全比對傳入的 tags, 不需要 scan 所有的 tag
+func (t *tagFilter) matcher(s scm.SCM, strategy *scm.TagMatcherStrategy, tags []string) (scm.TagMatcher, error) {
+	if strategy.IsExact() && t.isEmpty() {
+		return nil, nil
+	}
+	all := scm.AllMatcher{}
+	if len(tags) > 0 {
+		m, err := strategy.NewMatcher(tags)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, m)
+	}
+	if len(t.Exclude) > 0 {
+		m, err := strategy.NewMatcher(t.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, scm.NotMatcher{TagMatcher: m})
+	}
+	if len(t.AndRegex) > 0 {
+		m, err := scm.NewRegexMatcher(t.AndRegex)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, m)
+	}
+	if len(t.AndSemVer) > 0 {
+		m, err := scm.NewSemVerMatcher(t.AndSemVer)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, m)
+	}
+	if len(t.AndGlob) > 0 {
+		m, err := scm.NewGlobMatcher(t.AndGlob)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, m)
+	}
+	if t.Stage != "" {
+		all = append(all, scm.NewStageMatcher(stages, t.Stage))
+	}
+	if t.byRelease() {
+		releases, err := s.ListReleases()
+		if err != nil {
+			return nil, err
+		}
+		if len(t.Authors) > 0 {
+			all = append(all, scm.NewAuthorMatcher(releases, t.Authors))
+		}
+		if len(t.ExcludeAuthors) > 0 {
+			all = append(all, scm.NotMatcher{TagMatcher: scm.NewAuthorMatcher(releases, t.ExcludeAuthors)})
+		}
+		if t.OlderThan > 0 {
+			all = append(all, scm.NewAgeMatcher(releases, time.Duration(t.OlderThan)*24*time.Hour, time.Now()))
+		}
+	}
+	return all, nil
+}
@@ -3,13 +3,21 @@ package main
 import (
 	"fmt"
 	"github.com/sirupsen/logrus"
-	"github.com/softleader/s2i/pkg/github"
+	"github.com/softleader/s2i/pkg/git"
+	"github.com/softleader/s2i/pkg/scm"
 	"github.com/spf13/cobra"
+	"io"
 	"os"
 )
 
 const pluginTagListDesc = `列出 tag 名稱, 發佈時間及發佈人員
 
+s2i 會合併 tags 及 releases, 以 tag 名稱列出並依照 semantic version 由新到舊排序
+只有 tag 沒有 release 的會標示為 'tag only', 只有 release 沒有 tag 的 (如 draft release) 會標示為 'release only'
+傳入 '--limit' 可以限制列出的數量, 如列出最新的 5 個 tag:
+
+	$ slctl s2i tag list .+ -r --limit 5
+
 傳入 '--interactive' 可以開啟互動模式
 
 	$ s2i tag list TAG..
@@ -17,7 +25,7 @@ const pluginTagListDesc = `列出 tag 名稱, 發佈時間及發佈人員
 
 s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調整:
 
-	- git 資訊: '--source-owner', '--source-repo'
+	- git 資訊: '--source-owner', '--source-repo', 預設從名為 origin 的 remote 收集, 可傳入 '--remote' 指定
 
 傳入 '--regex' 將以 regular expression 方式模糊過濾 tag, 並列出之
 
@@ -28,17 +36,39 @@ s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調
 
 	$ slctl s2i tag list RANGE.. -s
 
-模糊過濾 flag ('-r' 或 '-s' 等) 使用上請注意: 
+傳入 '--stage' 只列出該 stage 的 pre-release, 可以與其他過濾條件一起使用, 不傳入 tag 時會列出所有該 stage 的 pre-release
+
+	$ slctl s2i tag list --stage rc
+	$ slctl s2i tag list ^1.2 -r --stage beta
+
+傳入 '--output' 可以調整輸出格式, 可以是 table (預設), json, yaml 或 name (只列出 tag 名稱), 方便與其他 script 整合
+
+	$ slctl s2i tag list ^1. -r -o json
+	$ slctl s2i tag list ^1. -r -o name | xargs -n1 echo
+
+傳入 '--glob' 將以 glob pattern 方式模糊過濾 tag, 並列出之
+
+	$ slctl s2i tag list "1.2.*" -g
+
+可以再組合 '--exclude', '--and-regex', '--and-semver', '--and-glob', '--author', '--exclude-author' 及 '--older-than' 等過濾條件,
+所有條件之間都是 AND, 說明請參考 'tag delete -h'
+
+	$ slctl s2i tag list "<2.0.0" -s --stage alpha --exclude-author matt
+
+模糊過濾 flag ('-r', '-s', '-g' 或其他過濾條件) 使用上請注意: 
 - 將會 scan 所有 GitHub 上所有的 tag, 效能自然會比完全比對 tag 來得差
-- 判斷先後順序依序為: '-r', '-s'
+- 判斷先後順序依序為: '-r', '-s', '-g'
 `
 
 type tagListCmd struct {
-	Tags                      []string
-	SourceOwner               string `yaml:"source-owner"`
-	SourceRepo                string `yaml:"source-repo"`
-	Interactive               bool
-	github.TagMatcherStrategy `yaml:"tag-matcher-strategy"`
+	Tags                   []string
+	SourceOwner            string `yaml:"source-owner"`
+	SourceRepo             string `yaml:"source-repo"`
+	Interactive            bool
+	Output                 string
+	Limit                  int
+	scm.TagMatcherStrategy `yaml:"tag-matcher-strategy"`
+	tagFilter              `yaml:",inline"`
 }
 
 func newTagListCmd() *cobra.Command {
@@ -50,15 +80,16 @@ func newTagListCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(c.SourceOwner) == 0 || len(c.SourceRepo) == 0 {
 				if pwd, err := os.Getwd(); err == nil {
-					t, owner, repo := github.Remote(logrus.StandardLogger(), pwd)
-					if len(t) != 0 { // 代表此 repo 是用指定 token clone 的, 因此換掉這次 global 的 token
-						token = t
+					r, err := git.FindRemote(logrus.StandardLogger(), pwd, remote)
+					if err != nil {
+						return err
 					}
+					useRemote(r)
 					if len(c.SourceOwner) == 0 {
-						c.SourceOwner = owner
+						c.SourceOwner = r.Owner
 					}
 					if len(c.SourceRepo) == 0 {
-						c.SourceRepo = repo
+						c.SourceRepo = r.Repo
 					}
 				}
 			}
@@ -68,10 +99,13 @@ func newTagListCmd() *cobra.Command {
 					return err
 				}
 			}
-			if len := len(c.Tags); len == 0 {
+			if len := len(c.Tags); len == 0 && c.tagFilter.isEmpty() {
 				return fmt.Errorf("requires at least 1 arg(s), only received %v", len)
 			}
-			return c.run()
+			if err := checkOutput(c.Output); err != nil {
+				return err
+			}
+			return c.run(cmd.OutOrStdout())
 		},
 	}
 
@@ -81,23 +115,33 @@ func newTagListCmd() *cobra.Command {
 	f.StringVar(&c.SourceRepo, "source-repo", c.SourceRepo, "name of repo to list tag")
 	f.BoolVarP(&c.Regex, "regex", "r", false, "matches tag by regex (bad performance warning, it'll scan over all tags of the repo)")
 	f.BoolVarP(&c.SemVer, "semver", "s", false, "matches tag by semantic versioning (bad performance warning, it'll scan over all tags of the repo)")
+	f.StringVarP(&c.Output, "output", "o", outputTable, "output format, one of: table, json, yaml, name")
+	f.IntVar(&c.Limit, "limit", 0, "maximum number of tags to list, 0 for no limit")
+	f.BoolVarP(&c.Glob, "glob", "g", false, "matches tag by glob pattern, e.g. 1.2.* (bad performance warning, it'll scan over all tags of the repo)")
+	c.tagFilter.flags(f, true)
 	return cmd
 }
 
-func (c *tagListCmd) run() error {
-	if c.Regex {
-		matcher, err := github.NewRegexMatcher(c.Tags)
-		if err != nil {
-			return err
-		}
-		return github.ListReleaseByMatcher(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher)
+func (c *tagListCmd) run(out io.Writer) error {
+	s, err := newSCM(c.SourceOwner, c.SourceRepo)
+	if err != nil {
+		return err
+	}
+	matcher, err := c.tagFilter.matcher(s, &c.TagMatcherStrategy, c.Tags)
+	if err != nil {
+		return err
+	}
+	var releases []*scm.Release
+	if matcher != nil {
+		releases, err = scm.FindTagsByMatcher(logrus.StandardLogger(), s, matcher)
+	} else {
+		releases, err = scm.FindTags(logrus.StandardLogger(), s, c.Tags)
+	}
+	if err != nil {
+		return err
 	}
-	if c.SemVer {
-		matcher, err := github.NewSemVerMatcher(c.Tags)
-		if err != nil {
-			return err
-		}
-		return github.ListReleaseByMatcher(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher)
+	if c.Limit > 0 && len(releases) > c.Limit {
+		releases = releases[:c.Limit]
 	}
-	return github.ListRelease(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.Tags)
+	return printReleases(out, c.Output, releases)
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/git"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const pluginTagMoveDesc = `將已存在的 tag 移到另一個 branch 或 commit SHA 上
+
+	$ s2i tag move TAG REF
+
+TAG 必須是有效的 semver2 版號 (允許 'v' 開頭), 預設移動後為 lightweight tag, 可以傳入 '--message', '--sign' 等建立 annotated tag, 說明請參考 'tag create -h'
+
+	$ s2i tag move 1.2.3 a1b2c3d -m "re-tag 1.2.3"
+
+GitLab 及 Gitea 沒有更新 tag 的 api, s2i 會先刪除再重新建立, 為了避免連同 release 一起被刪除, tag 已有 release 時將會中止
+
+s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調整:
+
+	- git 資訊: '--source-owner', '--source-repo', 預設從名為 origin 的 remote 收集, 可傳入 '--remote' 指定
+`
+
+type tagMoveCmd struct {
+	Tag         string
+	Ref         string
+	SourceOwner string `yaml:"source-owner"`
+	SourceRepo  string `yaml:"source-repo"`
+	tagMessage  `yaml:",inline"`
+}
+
+func newTagMoveCmd() *cobra.Command {
+	c := &tagMoveCmd{}
+	cmd := &cobra.Command{
+		Use:     "move <TAG> <REF>",
+		Aliases: []string{"mv"},
+		Short:   "move an existing tag to another ref",
+		Long:    pluginTagMoveDesc,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(c.SourceOwner) == 0 || len(c.SourceRepo) == 0 {
+				if pwd, err := os.Getwd(); err == nil {
+					r, err := git.FindRemote(logrus.StandardLogger(), pwd, remote)
+					if err != nil {
+						return err
+					}
+					useRemote(r)
+					if len(c.SourceOwner) == 0 {
+						c.SourceOwner = r.Owner
+					}
+					if len(c.SourceRepo) == 0 {
+						c.SourceRepo = r.Repo
+					}
+				}
+			}
+			c.Tag, c.Ref = args[0], args[1]
+			if err := scm.CheckVersion(c.Tag); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&c.SourceOwner, "source-owner", c.SourceOwner, "name of the owner (user or org) of the repo to move tag")
+	f.StringVar(&c.SourceRepo, "source-repo", c.SourceRepo, "name of repo to move tag")
+	c.tagMessage.flags(f)
+	return cmd
+}
+
+func (c *tagMoveCmd) run() error {
+	opts, err := c.tagMessage.options()
+	if err != nil {
+		return err
+	}
+	s, err := newSCM(c.SourceOwner, c.SourceRepo)
+	if err != nil {
+		return err
+	}
+	if err := s.MoveTag(c.Tag, c.Ref, opts); err != nil {
+		if err == scm.ErrNotFound {
+			return fmt.Errorf("tag %s not found on %s/%s, run 'tag create' to create it", c.Tag, c.SourceOwner, c.SourceRepo)
+		}
+		if _, ok := err.(*scm.RefNotFoundError); ok {
+			return fmt.Errorf("%s on %s/%s, tag %s is not moved", err, c.SourceOwner, c.SourceRepo, c.Tag)
+		}
+		return err
+	}
+	logrus.Infof("'%s' has been moved to %s", c.Tag, c.Ref)
+	return nil
+}
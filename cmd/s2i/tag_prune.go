@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/git"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+const pluginTagPruneDesc = `依照保留規則刪除舊的 pre-release 及其 tag
+
+final release 永遠不會被刪除, 保留規則有:
+
+	- '--keep': 每個 minor 版本 (如 1.2) 保留最新的 N 個 pre-release
+	- '--older-than': 只刪除發佈超過 N 天的 pre-release, 沒有發佈時間的 tag (tag only) 會被保留
+
+兩個規則都傳入時必須同時符合才會刪除, 也就是最新的 N 個一定會保留, 其餘的發佈超過 N 天才會刪除
+
+	$ s2i tag prune --keep 5
+	$ s2i tag prune --keep 5 --older-than 30
+
+傳入 TAG 可以限制要整理的範圍, 一樣支援 '--regex', '--semver', '--glob' 及 '--exclude', '--author' 等過濾條件, 說明請參考 'tag delete -h'
+
+	$ s2i tag prune "<2.x" -s --older-than 30
+	$ s2i tag prune --keep 5 --exclude-author matt
+
+傳入 '--delete-image' 會一併刪除 registry 上同名 tag 的 image, 說明請參考 'tag delete -h'
+
+刪除前會要求確認, 傳入 '--yes' 可略過確認, 被刪除的 pre-release 一樣可以透過 'tag restore' 重新建立
+
+傳入 '--dry-run' 只會列出將被刪除的 pre-release 及其原因, 不會真的作用到 GitHub 上
+
+	$ s2i tag prune --keep 5 --dry-run
+
+s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調整:
+
+	- git 資訊: '--source-owner', '--source-repo', 預設從名為 origin 的 remote 收集, 可傳入 '--remote' 指定
+`
+
+type tagPruneCmd struct {
+	Tags                   []string
+	SourceOwner            string `yaml:"source-owner"`
+	SourceRepo             string `yaml:"source-repo"`
+	DryRun                 bool   `yaml:"dry-run"`
+	Parallel               int
+	Yes                    bool
+	Keep                   int
+	scm.TagMatcherStrategy `yaml:"tag-matcher-strategy"`
+	tagFilter              `yaml:",inline"`
+	tagImage               `yaml:",inline"`
+}
+
+func newTagPruneCmd() *cobra.Command {
+	c := &tagPruneCmd{}
+	cmd := &cobra.Command{
+		Use:   "prune [TAG...]",
+		Short: "prune old pre-releases and their tags on GitHub by retention policy",
+		Long:  pluginTagPruneDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(c.SourceOwner) == 0 || len(c.SourceRepo) == 0 {
+				if pwd, err := os.Getwd(); err == nil {
+					r, err := git.FindRemote(logrus.StandardLogger(), pwd, remote)
+					if err != nil {
+						return err
+					}
+					useRemote(r)
+					if len(c.SourceOwner) == 0 {
+						c.SourceOwner = r.Owner
+					}
+					if len(c.SourceRepo) == 0 {
+						c.SourceRepo = r.Repo
+					}
+				}
+			}
+			c.Tags = args
+			return c.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&c.SourceOwner, "source-owner", c.SourceOwner, "name of the owner (user or org) of the repo to prune tag")
+	f.StringVar(&c.SourceRepo, "source-repo", c.SourceRepo, "name of repo to prune tag")
+	f.BoolVar(&c.DryRun, "dry-run", false, "list the pre-releases to prune and why, without deleting them")
+	f.IntVar(&c.Parallel, "parallel", 1, "number of tags to delete concurrently")
+	f.BoolVarP(&c.Yes, "yes", "y", false, "prune without confirmation")
+	f.IntVar(&c.Keep, "keep", 0, "keep the last N pre-releases per minor version, 0 for no limit")
+	f.IntVar(&c.OlderThan, "older-than", 0, "only prune pre-releases published more than N days ago, 0 for no limit")
+	f.BoolVarP(&c.Regex, "regex", "r", false, "limits the tags to prune by regex")
+	f.BoolVarP(&c.SemVer, "semver", "s", false, "limits the tags to prune by semantic versioning")
+	f.BoolVarP(&c.Glob, "glob", "g", false, "limits the tags to prune by glob pattern")
+	c.tagFilter.flags(f, false)
+	c.tagImage.flags(f)
+	return cmd
+}
+
+func (c *tagPruneCmd) run() error {
+	policy := scm.PrunePolicy{
+		KeepPerMinor: c.Keep,
+		OlderThan:    time.Duration(c.OlderThan) * 24 * time.Hour,
+	}
+	if err := policy.IsValid(); err != nil {
+		return err
+	}
+	s, err := newSCM(c.SourceOwner, c.SourceRepo)
+	if err != nil {
+		return err
+	}
+	filter := c.tagFilter
+	filter.OlderThan = 0 // 已由保留規則判斷
+	matcher, err := filter.matcher(s, &c.TagMatcherStrategy, c.Tags)
+	if err != nil {
+		return err
+	}
+	switch {
+	case matcher != nil:
+	case len(c.Tags) > 0:
+		matcher = scm.NewExactMatcher(c.Tags)
+	default:
+		matcher = scm.AllMatcher{} // 沒有傳入 TAG 時整理所有的 pre-release
+	}
+	releases, err := scm.FindTagsByMatcher(logrus.StandardLogger(), s, matcher)
+	if err != nil {
+		return err
+	}
+	candidates := scm.FindPrunable(releases, policy, time.Now())
+	if len(candidates) == 0 {
+		logrus.Infof("nothing to prune")
+		return nil
+	}
+	var tags []string
+	for _, candidate := range candidates {
+		logrus.Infof("'%s' %s", candidate.TagName, candidate.Reason)
+		tags = append(tags, candidate.TagName)
+	}
+	if c.DryRun {
+		logrus.Infof("%d pre-release(s) would be pruned", len(tags))
+		return nil
+	}
+	if !c.Yes {
+		if ok, err := confirm(fmt.Sprintf("Prune %d pre-release(s) and their tags?", len(tags))); err != nil || !ok {
+			return err
+		}
+	}
+	return deleteTags(c.tagImage.wrap(s, c.SourceRepo), c.SourceOwner, c.SourceRepo, tags, c.Parallel, false, true)
+}
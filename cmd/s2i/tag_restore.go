@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/git"
+	"github.com/softleader/s2i/pkg/journal"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const pluginTagRestoreDesc = `重新建立被 'tag delete' 或 'tag prune' 刪除的 tag 及其 release
+
+每個被刪除的 tag 都會記錄在 $HOME/.s2i/journal/<owner>/<repo>.jsonl 中,
+包含 tag 指向的 commit, release 的名稱, 內容及是否為 pre-release, 'tag restore' 會依照記錄重新建立在同一個 commit 上
+
+	$ s2i tag restore TAG..
+
+不傳入 TAG 時會重新建立 journal 中所有目前不存在的 tag, 傳入 '--dry-run' 只列出將被重新建立的 tag
+
+	$ s2i tag restore --dry-run
+
+s2i 會試著從當前目錄收集專案資訊, 你都可以自行傳入做調整:
+
+	- git 資訊: '--source-owner', '--source-repo', 預設從名為 origin 的 remote 收集, 可傳入 '--remote' 指定
+`
+
+type tagRestoreCmd struct {
+	Tags        []string
+	SourceOwner string `yaml:"source-owner"`
+	SourceRepo  string `yaml:"source-repo"`
+	DryRun      bool   `yaml:"dry-run"`
+}
+
+func newTagRestoreCmd() *cobra.Command {
+	c := &tagRestoreCmd{}
+	cmd := &cobra.Command{
+		Use:   "restore [TAG...]",
+		Short: "restore deleted tags and their releases on GitHub",
+		Long:  pluginTagRestoreDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(c.SourceOwner) == 0 || len(c.SourceRepo) == 0 {
+				if pwd, err := os.Getwd(); err == nil {
+					r, err := git.FindRemote(logrus.StandardLogger(), pwd, remote)
+					if err != nil {
+						return err
+					}
+					useRemote(r)
+					if len(c.SourceOwner) == 0 {
+						c.SourceOwner = r.Owner
+					}
+					if len(c.SourceRepo) == 0 {
+						c.SourceRepo = r.Repo
+					}
+				}
+			}
+			c.Tags = args
+			return c.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&c.SourceOwner, "source-owner", c.SourceOwner, "name of the owner (user or org) of the repo to restore tag")
+	f.StringVar(&c.SourceRepo, "source-repo", c.SourceRepo, "name of repo to restore tag")
+	f.BoolVar(&c.DryRun, "dry-run", false, "list the tags to restore without restoring them")
+	return cmd
+}
+
+func (c *tagRestoreCmd) run() error {
+	path, err := journal.Path(c.SourceOwner, c.SourceRepo)
+	if err != nil {
+		return err
+	}
+	entries, err := journal.Read(path)
+	if err != nil {
+		return fmt.Errorf("failed to read journal %s: %s", path, err)
+	}
+	if len(c.Tags) > 0 {
+		matcher := scm.NewExactMatcher(c.Tags)
+		var filtered []*journal.Entry
+		for _, e := range entries {
+			if matcher.Matches(e.Tag) {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = filtered
+	}
+	if len(entries) == 0 {
+		logrus.Infof("nothing to restore in %s", path)
+		return nil
+	}
+	s, err := newSCM(c.SourceOwner, c.SourceRepo)
+	if err != nil {
+		return err
+	}
+	tags, err := s.ListTags()
+	if err != nil {
+		return err
+	}
+	existing := scm.NewExactMatcher(tags)
+	var restored, skipped, failed int
+	for _, e := range entries {
+		if existing.Matches(e.Tag) || (e.Draft && draftExists(s, e.Tag)) {
+			logrus.Debugf("'%s' already exists, skipping", e.Tag)
+			skipped++
+			continue
+		}
+		if c.DryRun {
+			logrus.Infof("'%s' would be restored on %s", e.Tag, e.SHA)
+			restored++
+			continue
+		}
+		if err := journal.Restore(logrus.StandardLogger(), s, e); err != nil {
+			logrus.Errorf("'%s' failed: %s", e.Tag, err)
+			failed++
+			continue
+		}
+		logrus.Infof("'%s' has been restored on %s", e.Tag, e.SHA)
+		restored++
+	}
+	verb := "restored"
+	if c.DryRun {
+		verb = "would be restored"
+	}
+	logrus.Infof("%d %s, %d skipped, %d failed", restored, verb, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to restore %d tag(s)", failed)
+	}
+	return nil
+}
+
+// draftExists 判斷 draft release 是否仍存在, draft release 在 publish 前不會有 tag
+func draftExists(s scm.SCM, tag string) bool {
+	_, err := s.GetRelease(tag)
+	return err == nil
+}
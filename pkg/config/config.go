@@ -0,0 +1,76 @@
+package config
+
+import (
+	"github.com/mitchellh/go-homedir"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// FileName 是 s2i 設定檔的檔名
+	FileName = ".s2i.yaml"
+)
+
+// Paths 回傳所有可能的設定檔路徑, 依優先順序由低到高排列: user 層級 (home 目錄), repo 層級 (pwd)
+func Paths(pwd string) (paths []string) {
+	if home, err := homedir.Dir(); err == nil {
+		paths = append(paths, filepath.Join(home, FileName))
+	}
+	if pwd != "" {
+		paths = append(paths, filepath.Join(pwd, FileName))
+	}
+	return
+}
+
+// Load 依序讀取所有設定檔, 並 unmarshal 到 out 中, 後讀取的設定檔會覆蓋先前的值
+// 設定檔中最外層的 key 為所有 command 共用, section 下的 key 則只作用在該 command, 如:
+//
+//	deployer: http://softleader.com.tw:5678
+//	prerelease:
+//	  config-label: sqlServer
+func Load(log *logrus.Logger, pwd, section string, out interface{}) error {
+	for _, p := range Paths(pwd) {
+		if err := LoadFile(log, p, section, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile 讀取單一設定檔並 unmarshal 到 out 中, 檔案不存在時直接略過
+func LoadFile(log *logrus.Logger, path, section string, out interface{}) error {
+	log.Debugf("loading config: %s", path)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return unmarshal(b, section, out)
+}
+
+func unmarshal(b []byte, section string, out interface{}) error {
+	if err := yaml.Unmarshal(b, out); err != nil {
+		return err
+	}
+	if section == "" {
+		return nil
+	}
+	sections := make(map[string]interface{})
+	if err := yaml.Unmarshal(b, &sections); err != nil {
+		return err
+	}
+	s, found := sections[section]
+	if !found || s == nil {
+		return nil
+	}
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(b, out)
+}
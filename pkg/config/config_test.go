@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+)
+
+type cmd struct {
+	Deployer     string
+	ConfigServer string `yaml:"config-server"`
+	ConfigLabel  string `yaml:"config-label"`
+	ServiceID    string `yaml:"service-id"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	b := []byte(`deployer: http://localhost:5678
+config-label: shared
+prerelease:
+  config-label: sqlServer
+  service-id: abc
+release:
+  service-id: def`)
+
+	c := &cmd{
+		ConfigServer: "http://softleader.com.tw:8887",
+	}
+	if err := unmarshal(b, "prerelease", c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Deployer != "http://localhost:5678" {
+		t.Errorf("deployer should be http://localhost:5678, but got %q", c.Deployer)
+	}
+	if c.ConfigServer != "http://softleader.com.tw:8887" {
+		t.Errorf("config-server should be kept as default, but got %q", c.ConfigServer)
+	}
+	if c.ConfigLabel != "sqlServer" {
+		t.Errorf("config-label should be overridden by section, but got %q", c.ConfigLabel)
+	}
+	if c.ServiceID != "abc" {
+		t.Errorf("service-id should be abc, but got %q", c.ServiceID)
+	}
+}
+
+func TestUnmarshalMissingSection(t *testing.T) {
+	b := []byte(`deployer: http://localhost:5678`)
+	c := &cmd{}
+	if err := unmarshal(b, "release", c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Deployer != "http://localhost:5678" {
+		t.Errorf("deployer should be http://localhost:5678, but got %q", c.Deployer)
+	}
+}
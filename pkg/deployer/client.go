@@ -0,0 +1,118 @@
+package deployer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/resty.v1"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	maxMessageSize = 512
+)
+
+var (
+	// ErrServiceNotFound 代表 service 不存在於 deployer 上
+	ErrServiceNotFound = errors.New("service not found")
+
+	notFoundMessage = regexp.MustCompile(`(?i)no such service|service \S+ not found`)
+)
+
+// Client 代表一個 SoftLeader deployer client
+type Client struct {
+	c   *resty.Client
+	log *logrus.Logger
+}
+
+// NewClient 產生一個 deployer client, url 為 deployer 的網址, e.g. http://softleader.com.tw:5678
+func NewClient(url string) *Client {
+	return &Client{
+		log: logrus.StandardLogger(),
+		c: resty.New().
+			SetHostURL(strings.TrimSuffix(url, "/")).
+			SetDisableWarn(true).
+			SetTimeout(defaultTimeout).
+			SetHeader("Accept", "application/json"),
+	}
+}
+
+// SetLogger sets the logger
+func (c *Client) SetLogger(log *logrus.Logger) *Client {
+	c.log = log
+	return c
+}
+
+// SetVerbose enables verbose mode
+func (c *Client) SetVerbose(v bool) *Client {
+	c.c.SetDebug(v)
+	return c
+}
+
+// SetUserAgent 設定呼叫 deployer 時的 User-Agent, e.g. s2i/1.0.0
+func (c *Client) SetUserAgent(agent, version string) *Client {
+	c.c.SetHeader("User-Agent", fmt.Sprintf("%s/%s", agent, version))
+	return c
+}
+
+// SetTimeout 設定每個 request 的 timeout
+func (c *Client) SetTimeout(timeout time.Duration) *Client {
+	c.c.SetTimeout(timeout)
+	return c
+}
+
+// SetAuthToken 設定呼叫 deployer 時的 bearer token, 空字串代表 deployer 不需要驗證
+func (c *Client) SetAuthToken(token string) *Client {
+	if token != "" {
+		c.c.SetAuthToken(token)
+	}
+	return c
+}
+
+// Error 代表 deployer 回傳了非 2xx 的 status code
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("deployer responded %d: %s", e.StatusCode, e.Message)
+}
+
+// newError 將非 2xx 的 response 轉換成 error, service 不存在時回傳 ErrServiceNotFound
+func newError(resp *resty.Response) error {
+	e := &Error{StatusCode: resp.StatusCode(), Message: message(resp)}
+	if e.StatusCode == http.StatusNotFound || notFoundMessage.MatchString(e.Message) {
+		return ErrServiceNotFound
+	}
+	return e
+}
+
+// message 從 response body 取出錯誤訊息, 支援 {"message": ...} 或 {"error": ...} 的 json, 其餘視為純文字
+func message(resp *resty.Response) string {
+	var body struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(resp.Body(), &body); err == nil {
+		if body.Message != "" {
+			return body.Message
+		}
+		if body.Error != "" {
+			return body.Error
+		}
+	}
+	text := strings.TrimSpace(string(resp.Body()))
+	if text == "" {
+		return resp.Status()
+	}
+	if len(text) > maxMessageSize {
+		text = text[:maxMessageSize] + "..."
+	}
+	return text
+}
@@ -0,0 +1,112 @@
+package deployer
+
+import (
+	"fmt"
+	"github.com/softleader/s2i/pkg/docker"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const inspect = `[{
+	"ID": "abc",
+	"Spec": {
+		"Name": "app",
+		"TaskTemplate": {"ContainerSpec": {"Image": "hub.softleader.com.tw/app:1.2.3@sha256:123"}},
+		"Mode": {"Replicated": {"Replicas": 2}}
+	},
+	"PreviousSpec": {
+		"Name": "app",
+		"TaskTemplate": {"ContainerSpec": {"Image": "hub.softleader.com.tw/app:1.2.2@sha256:456"}}
+	},
+	"Endpoint": {"Ports": [{"Protocol": "tcp", "TargetPort": 80, "PublishedPort": 8080}]}
+}]`
+
+func newFakeDeployer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/services/update/abc", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"unauthorized"}`)
+			return
+		}
+		if r.Header.Get("User-Agent") != "s2i/1.0.0" {
+			t.Errorf("unexpected user agent %q", r.Header.Get("User-Agent"))
+		}
+		if actual := r.URL.Query().Get("image"); actual != "hub.softleader.com.tw/app:1.2.3" {
+			t.Errorf("unexpected image %q", actual)
+		}
+	})
+	mux.HandleFunc("/services/update/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "Error: No such service: xyz")
+	})
+	mux.HandleFunc("/services/filter", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"ID":"abc","Name":%q}]`, r.URL.Query().Get("label"))
+	})
+	mux.HandleFunc("/services/inspect/abc", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, inspect)
+	})
+	mux.HandleFunc("/services/rollback/abc", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error":"rollback failed"}`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestUpdateService(t *testing.T) {
+	server := newFakeDeployer(t)
+	defer server.Close()
+	image := &docker.Image{Name: "app", Tag: "1.2.3"}
+
+	c := NewClient(server.URL+"/").SetUserAgent("s2i", "1.0.0")
+	if err := c.UpdateService("abc", image, true); err == nil || err.Error() != "deployer responded 401: unauthorized" {
+		t.Errorf("expected unauthorized, but got %v", err)
+	}
+	c.SetAuthToken("secret")
+	if err := c.UpdateService("abc", image, true); err != nil {
+		t.Error(err)
+	}
+	if err := c.UpdateService("xyz", image, true); err != ErrServiceNotFound {
+		t.Errorf("expected ErrServiceNotFound, but got %v", err)
+	}
+}
+
+func TestServices(t *testing.T) {
+	server := newFakeDeployer(t)
+	defer server.Close()
+	c := NewClient(server.URL)
+
+	services, err := c.FilterServiceByApp("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || services[0].Name != "app=app" {
+		t.Errorf("expected filtered by label app=app, but got %v", services)
+	}
+
+	s, err := c.InspectService("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := s.Image(); actual != "hub.softleader.com.tw/app:1.2.3" {
+		t.Errorf("unexpected image %q", actual)
+	}
+	if actual := s.PreviousImage(); actual != "hub.softleader.com.tw/app:1.2.2" {
+		t.Errorf("unexpected previous image %q", actual)
+	}
+	if actual := s.Replicas(); actual != "2" {
+		t.Errorf("unexpected replicas %q", actual)
+	}
+	if actual := s.Ports(); actual != "8080->80/tcp" {
+		t.Errorf("unexpected ports %q", actual)
+	}
+	if _, err := c.InspectService("xyz"); err != ErrServiceNotFound {
+		t.Errorf("expected ErrServiceNotFound, but got %v", err)
+	}
+
+	err = c.RollbackService("abc")
+	if e, ok := err.(*Error); !ok || e.StatusCode != http.StatusInternalServerError || e.Message != "rollback failed" {
+		t.Errorf("expected typed error, but got %v", err)
+	}
+}
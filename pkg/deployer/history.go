@@ -0,0 +1,94 @@
+package deployer
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/mitchellh/go-homedir"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	historyDir = ".s2i/deployments"
+)
+
+// Deployment 代表一次 service 的更新, 記錄更新前的 image 以便日後 rollback
+type Deployment struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	ServiceID string    `json:"service_id"`
+	Image     string    `json:"image"`
+	Previous  string    `json:"previous"`
+}
+
+// HistoryPath 回傳 service 的更新記錄路徑, e.g. $HOME/.s2i/deployments/<serviceID>.jsonl
+func HistoryPath(serviceID string) (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, historyDir, serviceID+".jsonl"), nil
+}
+
+// History 以每行一筆 json 的格式記錄 service 的更新, 可同時被多個 goroutine 寫入
+type History struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewHistory 建立寫入 path 的 History
+func NewHistory(path string) *History {
+	return &History{path: path}
+}
+
+// Path 回傳更新記錄的路徑
+func (h *History) Path() string {
+	return h.path
+}
+
+// Append 新增一筆記錄到最後
+func (h *History) Append(d *Deployment) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Last 回傳最後一筆記錄, 沒有任何記錄時回傳 nil
+func (h *History) Last() (last *Deployment, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		d := &Deployment{}
+		if err := json.Unmarshal(scanner.Bytes(), d); err != nil {
+			return nil, err
+		}
+		last = d
+	}
+	return last, scanner.Err()
+}
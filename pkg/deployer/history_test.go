@@ -0,0 +1,36 @@
+package deployer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deployments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	h := NewHistory(filepath.Join(dir, "nested", "abc.jsonl"))
+
+	if last, err := h.Last(); err != nil || last != nil {
+		t.Errorf("expected no history, but got %v, %v", last, err)
+	}
+	for _, d := range []*Deployment{
+		{ServiceID: "abc", Image: "app:2", Previous: "app:1"},
+		{ServiceID: "abc", Image: "app:3", Previous: "app:2"},
+	} {
+		if err := h.Append(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	last, err := h.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Image != "app:3" || last.Previous != "app:2" {
+		t.Errorf("expected the last deployment, but got %+v", last)
+	}
+}
@@ -0,0 +1,173 @@
+package deployer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/softleader/s2i/pkg/docker"
+	"strings"
+	"time"
+)
+
+const (
+	pathServicesTasks = "/services/ps/%s"
+
+	// maxFailedTasks 代表以新 image 失敗超過幾次就視為 tasks 不斷重啟
+	maxFailedTasks = 3
+)
+
+// Task 是 'docker service ps' 中 s2i 會用到的欄位
+type Task struct {
+	ID           string
+	Slot         int
+	DesiredState string
+	Status       struct {
+		State   string
+		Message string
+		Err     string
+	}
+	Spec struct {
+		ContainerSpec struct {
+			Image string
+		}
+	}
+}
+
+// Image 回傳 task 執行的 image, 不包含 docker 自動加上的 digest
+func (t *Task) Image() string {
+	return stripDigest(t.Spec.ContainerSpec.Image)
+}
+
+// Failed 回傳 task 是否已失敗
+func (t *Task) Failed() bool {
+	switch t.Status.State {
+	case "failed", "rejected", "orphaned":
+		return true
+	}
+	return false
+}
+
+// ServiceTasks 查詢 service 的 tasks, 內容同 'docker service ps'
+func (c *Client) ServiceTasks(serviceID string) ([]Task, error) {
+	resp, err := c.c.R().
+		Get(fmt.Sprintf(pathServicesTasks, serviceID))
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, newError(resp)
+	}
+	var tasks []Task
+	if err = json.Unmarshal(resp.Body(), &tasks); err != nil {
+		return nil, fmt.Errorf("failed to parse tasks from deployer: %s", err)
+	}
+	return tasks, nil
+}
+
+// Rollout 代表 service 更新到 image 的進度
+type Rollout struct {
+	Image         string
+	Desired       int    // 應執行的 tasks 數量
+	Running       int    // 已以新 image 執行中的 tasks 數量
+	Outdated      int    // 仍以舊 image 執行中的 tasks 數量
+	Failed        int    // 以新 image 失敗的 tasks 數量
+	UpdateState   string // service 的更新狀態, e.g. updating, completed, paused
+	UpdateMessage string
+	LastError     string // 最近一次失敗的 task 的錯誤訊息
+}
+
+// NewRollout 依照 service 及其 tasks 計算更新到 image 的進度
+func NewRollout(s *ServiceInspect, tasks []Task, image string) *Rollout {
+	r := &Rollout{Image: image}
+	if s.UpdateStatus != nil {
+		r.UpdateState, r.UpdateMessage = s.UpdateStatus.State, s.UpdateStatus.Message
+	}
+	for _, t := range tasks {
+		current := t.Image() == image
+		switch {
+		case current && t.Failed():
+			r.Failed++
+			if r.LastError == "" {
+				r.LastError = t.Status.Err
+			}
+		case t.DesiredState != "running":
+		case t.Status.State != "running":
+		case current:
+			r.Running++
+		default:
+			r.Outdated++
+		}
+		if t.DesiredState == "running" && !s.replicated() {
+			r.Desired++
+		}
+	}
+	if s.replicated() {
+		r.Desired = int(*s.Spec.Mode.Replicated.Replicas)
+	}
+	return r
+}
+
+// Done 回傳是否所有的 tasks 都已以新 image 執行
+func (r *Rollout) Done() bool {
+	return r.Running >= r.Desired && r.Outdated == 0 && (r.UpdateState == "" || r.UpdateState == "completed")
+}
+
+// Err 回傳 rollout 失敗的原因, 如 docker 中止或回復了更新, 或是 tasks 不斷重啟
+func (r *Rollout) Err() error {
+	if strings.HasPrefix(r.UpdateState, "rollback") || r.UpdateState == "paused" {
+		return fmt.Errorf("update of %s is %s: %s", r.Image, r.UpdateState, r.UpdateMessage)
+	}
+	if r.Failed >= maxFailedTasks {
+		return fmt.Errorf("tasks of %s keep restarting, %d failed: %s", r.Image, r.Failed, r.LastError)
+	}
+	return nil
+}
+
+func (r *Rollout) String() string {
+	s := fmt.Sprintf("%d/%d tasks running %s", r.Running, r.Desired, r.Image)
+	if r.Outdated > 0 {
+		s += fmt.Sprintf(", %d outdated", r.Outdated)
+	}
+	if r.Failed > 0 {
+		s += fmt.Sprintf(", %d failed", r.Failed)
+	}
+	return s
+}
+
+// WaitForRollout 每隔 interval 查詢一次 service, 直到所有 tasks 都以 image 執行, rollout 失敗或超過 timeout 時回傳 error
+func (c *Client) WaitForRollout(serviceID string, image *docker.Image, timeout, interval time.Duration) error {
+	c.log.Printf("Waiting for docker service id: %s to run %s", serviceID, image)
+	deadline := time.Now().Add(timeout)
+	var last string
+	for {
+		r, err := c.rollout(serviceID, image.String())
+		if err != nil {
+			return err
+		}
+		if progress := r.String(); progress != last {
+			c.log.Printf("  %s: %s", serviceID, progress)
+			last = progress
+		}
+		if err := r.Err(); err != nil {
+			return err
+		}
+		if r.Done() {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for service %s to run %s", timeout, serviceID, image)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (c *Client) rollout(serviceID, image string) (*Rollout, error) {
+	s, err := c.InspectService(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := c.ServiceTasks(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	return NewRollout(s, tasks, image), nil
+}
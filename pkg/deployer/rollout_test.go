@@ -0,0 +1,84 @@
+package deployer
+
+import (
+	"fmt"
+	"github.com/softleader/s2i/pkg/docker"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func task(image, desired, state string) Task {
+	t := Task{DesiredState: desired}
+	t.Status.State = state
+	t.Spec.ContainerSpec.Image = image + "@sha256:123"
+	return t
+}
+
+func TestNewRollout(t *testing.T) {
+	replicas := uint64(2)
+	s := &ServiceInspect{}
+	s.Spec.Mode.Replicated = &struct{ Replicas *uint64 }{Replicas: &replicas}
+
+	r := NewRollout(s, []Task{
+		task("app:2", "running", "running"),
+		task("app:1", "running", "running"),
+		task("app:1", "shutdown", "shutdown"),
+	}, "app:2")
+	if r.Done() || r.String() != "1/2 tasks running app:2, 1 outdated" {
+		t.Errorf("expected rollout in progress, but got %s", r)
+	}
+
+	r = NewRollout(s, []Task{
+		task("app:2", "running", "running"),
+		task("app:2", "running", "running"),
+		task("app:1", "shutdown", "shutdown"),
+	}, "app:2")
+	if !r.Done() || r.Err() != nil {
+		t.Errorf("expected rollout done, but got %s", r)
+	}
+
+	crashed := task("app:2", "shutdown", "failed")
+	crashed.Status.Err = "task: non-zero exit (1)"
+	r = NewRollout(s, []Task{crashed, crashed, crashed, task("app:1", "running", "running")}, "app:2")
+	if err := r.Err(); err == nil || !strings.Contains(err.Error(), "non-zero exit") {
+		t.Errorf("expected tasks keep restarting, but got %v", err)
+	}
+
+	s.UpdateStatus = &UpdateStatus{State: "rollback_started", Message: "update paused due to failure"}
+	if err := NewRollout(s, nil, "app:2").Err(); err == nil {
+		t.Error("expected rollback to fail the rollout")
+	}
+}
+
+func TestWaitForRollout(t *testing.T) {
+	var polls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/services/inspect/abc", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"ID":"abc","Spec":{"Mode":{"Replicated":{"Replicas":1}}}}]`)
+	})
+	mux.HandleFunc("/services/ps/abc", func(w http.ResponseWriter, r *http.Request) {
+		image := "app:1"
+		if atomic.AddInt32(&polls, 1) > 2 {
+			image = "hub.softleader.com.tw/app:2"
+		}
+		fmt.Fprintf(w, `[{"DesiredState":"running","Status":{"State":"running"},"Spec":{"ContainerSpec":{"Image":%q}}}]`, image)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := NewClient(server.URL)
+
+	image := &docker.Image{Name: "app", Tag: "2"}
+	if err := c.WaitForRollout("abc", image, time.Second, time.Millisecond); err != nil {
+		t.Error(err)
+	}
+	if polls != 3 {
+		t.Errorf("expected 3 polls, but got %d", polls)
+	}
+	if err := c.WaitForRollout("abc", &docker.Image{Name: "app", Tag: "3"}, 10*time.Millisecond, time.Millisecond); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timed out, but got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package deployer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/softleader/s2i/pkg/docker"
+	"strings"
+)
+
+const (
+	pathServicesUpdate   = "/services/update/%s"
+	pathServicesFilter   = "/services/filter"
+	pathServicesInspect  = "/services/inspect/%s"
+	pathServicesRollback = "/services/rollback/%s"
+)
+
+// DockerService 包含了 docker service 的資訊
+type DockerService struct {
+	ID       string `json:"id" yaml:"id"`
+	Image    string `json:"image" yaml:"image"`
+	Mode     string `json:"mode" yaml:"mode"`
+	Name     string `json:"name" yaml:"name"`
+	Ports    string `json:"ports" yaml:"ports"`
+	Replicas string `json:"replicas" yaml:"replicas"`
+}
+
+// UpdateService 將 service 更新為 image
+func (c *Client) UpdateService(serviceID string, image *docker.Image, skipSlack bool) error {
+	c.log.Printf("Updating docker service id: %s", serviceID)
+	params := make(map[string]string)
+	params["image"] = image.String()
+	if skipSlack {
+		params["skip-slack"] = "1"
+	}
+	resp, err := c.c.R().
+		SetQueryParams(params).
+		Get(fmt.Sprintf(pathServicesUpdate, serviceID))
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return newError(resp)
+	}
+	return nil
+}
+
+// FilterServiceByApp 依照 label=app 查詢 docker service
+func (c *Client) FilterServiceByApp(app string) ([]DockerService, error) {
+	params := make(map[string]string)
+	params["label"] = fmt.Sprintf("app=%s", app)
+	return c.FilterService(params)
+}
+
+// FilterService 依照條件查詢 service
+func (c *Client) FilterService(params map[string]string) ([]DockerService, error) {
+	resp, err := c.c.R().
+		SetQueryParams(params).
+		Get(pathServicesFilter)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, newError(resp)
+	}
+	var services []DockerService
+	if err = json.Unmarshal(resp.Body(), &services); err != nil {
+		return nil, fmt.Errorf("failed to parse services from deployer: %s", err)
+	}
+	return services, nil
+}
+
+// InspectService 查詢 service 的詳細資訊, 內容同 'docker service inspect'
+func (c *Client) InspectService(serviceID string) (*ServiceInspect, error) {
+	resp, err := c.c.R().
+		Get(fmt.Sprintf(pathServicesInspect, serviceID))
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, newError(resp)
+	}
+	return parseInspect(resp.Body())
+}
+
+// RollbackService 將 service 回復到上一次更新前的設定, 同 'docker service rollback'
+func (c *Client) RollbackService(serviceID string) error {
+	c.log.Printf("Rolling back docker service id: %s", serviceID)
+	resp, err := c.c.R().
+		Get(fmt.Sprintf(pathServicesRollback, serviceID))
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return newError(resp)
+	}
+	return nil
+}
+
+// ServiceInspect 是 'docker service inspect' 中 s2i 會用到的欄位
+type ServiceInspect struct {
+	ID           string
+	Spec         ServiceSpec
+	PreviousSpec *ServiceSpec
+	Endpoint     struct {
+		Ports []PortConfig
+	}
+	UpdateStatus *UpdateStatus
+}
+
+// ServiceSpec 是 service 的設定
+type ServiceSpec struct {
+	Name         string
+	Labels       map[string]string
+	TaskTemplate struct {
+		ContainerSpec struct {
+			Image string
+		}
+	}
+	Mode struct {
+		Replicated *struct {
+			Replicas *uint64
+		}
+		Global *struct{}
+	}
+}
+
+// PortConfig 是 service 對外開放的 port
+type PortConfig struct {
+	Protocol      string
+	TargetPort    uint32
+	PublishedPort uint32
+}
+
+// UpdateStatus 是 service 最近一次更新的狀態
+type UpdateStatus struct {
+	State   string
+	Message string
+}
+
+// Name 回傳 service 名稱
+func (s *ServiceInspect) Name() string {
+	return s.Spec.Name
+}
+
+// Image 回傳 service 當前的 image, 不包含 docker 自動加上的 digest
+func (s *ServiceInspect) Image() string {
+	return stripDigest(s.Spec.TaskTemplate.ContainerSpec.Image)
+}
+
+// PreviousImage 回傳 service 上一次更新前的 image, 沒有更新過時回傳空字串
+func (s *ServiceInspect) PreviousImage() string {
+	if s.PreviousSpec == nil {
+		return ""
+	}
+	return stripDigest(s.PreviousSpec.TaskTemplate.ContainerSpec.Image)
+}
+
+// Replicas 回傳 service 設定的 replicas 數量, global mode 時回傳 "global"
+func (s *ServiceInspect) Replicas() string {
+	switch {
+	case s.Spec.Mode.Global != nil:
+		return "global"
+	case s.replicated():
+		return fmt.Sprint(*s.Spec.Mode.Replicated.Replicas)
+	}
+	return ""
+}
+
+// replicated 回傳 service 是否為 replicated mode 並設定了 replicas
+func (s *ServiceInspect) replicated() bool {
+	return s.Spec.Mode.Replicated != nil && s.Spec.Mode.Replicated.Replicas != nil
+}
+
+// Ports 回傳對外開放的 port, e.g. 8080->80/tcp
+func (s *ServiceInspect) Ports() string {
+	var ports []string
+	for _, p := range s.Endpoint.Ports {
+		ports = append(ports, fmt.Sprintf("%d->%d/%s", p.PublishedPort, p.TargetPort, p.Protocol))
+	}
+	return strings.Join(ports, ", ")
+}
+
+// parseInspect 解析 inspect 的結果, 'docker service inspect' 回傳的是 array, 也支援單一物件
+func parseInspect(b []byte) (*ServiceInspect, error) {
+	var services []*ServiceInspect
+	if err := json.Unmarshal(b, &services); err == nil {
+		if len(services) == 0 {
+			return nil, ErrServiceNotFound
+		}
+		return services[0], nil
+	}
+	s := &ServiceInspect{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("failed to parse service from deployer: %s", err)
+	}
+	return s, nil
+}
+
+// stripDigest 移除 docker 自動加在 image 後的 digest, e.g. app:1.0.0@sha256:... 為 app:1.0.0
+func stripDigest(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i]
+	}
+	return image
+}
@@ -30,7 +30,7 @@ func ContainsMultiStageBuilds(log *logrus.Logger, pwd string) bool {
 }
 
 // Build to exec 'docker build' command
-func Build(log *logrus.Logger, image *SoftleaderHubImage) error {
+func Build(log *logrus.Logger, image *Image) error {
 	cmd := exec.Command("docker", "build", "-t", image.String(), ".")
 	if log.IsLevelEnabled(logrus.DebugLevel) {
 		log.Out.Write([]byte(fmt.Sprintln(strings.Join(cmd.Args, " "))))
@@ -44,7 +44,7 @@ func Build(log *logrus.Logger, image *SoftleaderHubImage) error {
 }
 
 // Push to exec 'docker push' command
-func Push(log *logrus.Logger, image *SoftleaderHubImage) error {
+func Push(log *logrus.Logger, image *Image) error {
 	cmd := exec.Command("docker", "push", image.String())
 	if log.IsLevelEnabled(logrus.DebugLevel) {
 		log.Out.Write([]byte(fmt.Sprintln(strings.Join(cmd.Args, " "))))
@@ -58,7 +58,7 @@ func Push(log *logrus.Logger, image *SoftleaderHubImage) error {
 }
 
 // Rmi to exec 'docker rmi' command
-func Rmi(log *logrus.Logger, image *SoftleaderHubImage) error {
+func Rmi(log *logrus.Logger, image *Image) error {
 	cmd := exec.Command("docker", "rmi", image.String())
 	if log.IsLevelEnabled(logrus.DebugLevel) {
 		log.Out.Write([]byte(fmt.Sprintln(strings.Join(cmd.Args, " "))))
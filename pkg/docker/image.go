@@ -7,52 +7,96 @@ import (
 )
 
 const (
-	softleaderHub = "hub.softleader.com.tw"
+	// SoftleaderHub 是松凌科技的 docker registry
+	SoftleaderHub = "hub.softleader.com.tw"
 )
 
-// SoftleaderHubImage 表示該 image 會放在 hub.softleader.com.tw
-type SoftleaderHubImage struct {
+// Image 代表要 build 及 push 的 image, Registry 為空時使用 hub.softleader.com.tw
+type Image struct {
+	Registry  string `yaml:"registry,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
 	Name, Tag string
 }
 
-// SetPreRelease 設定 tag 的 pre-release 版號
-func (i *SoftleaderHubImage) SetPreRelease(preRelease string) {
+// Host 回傳 image 所在的 registry
+func (i *Image) Host() string {
+	if i.Registry == "" {
+		return SoftleaderHub
+	}
+	return i.Registry
+}
+
+// Repository 回傳 image 在 registry 中的名稱, 有 namespace 時為 namespace/name, e.g. team/app
+func (i *Image) Repository() string {
+	if ns := strings.Trim(i.Namespace, "/"); ns != "" {
+		return ns + "/" + i.Name
+	}
+	return i.Name
+}
+
+// SetPreRelease 設定 tag 的 pre-release 版號, 傳入多個 identifier 時以 '.' 串接, e.g. 0 及 1 為 1.2.3-0.1;
+// tag 不是 semver 或 identifier 不合法 (只能包含英數字及 '-') 時回傳 error 且不會修改 tag
+func (i *Image) SetPreRelease(identifiers ...string) error {
 	version := strings.TrimPrefix(i.Tag, "v")
 	sv, err := semver.Parse(version)
 	if err != nil {
-		return
+		return fmt.Errorf("tag %q is not a valid semantic version: %s", i.Tag, err)
 	}
 	sv.Pre = nil
-	prv, err := semver.NewPRVersion(preRelease)
-	if err != nil {
-		return
+	for _, id := range identifiers {
+		prv, err := semver.NewPRVersion(id)
+		if err != nil {
+			return fmt.Errorf("invalid pre-release identifier %q: %s", id, err)
+		}
+		sv.Pre = append(sv.Pre, prv)
 	}
-	sv.Pre = append(sv.Pre, prv)
 	pr := sv.String()
 	if strings.HasPrefix(i.Tag, "v") {
 		pr = "v" + pr
 	}
 	i.Tag = pr
+	return nil
 }
 
-// String 返回適用於 hub.softleader.com.tw 的 image 全名
-func (i *SoftleaderHubImage) String() string {
-	return fmt.Sprintf("%s/%s:%s", softleaderHub, i.Name, i.Tag)
+// String 返回 image 全名, e.g. hub.softleader.com.tw/team/app:1.2.3
+func (i *Image) String() string {
+	return fmt.Sprintf("%s/%s:%s", i.Host(), i.Repository(), i.Tag)
 }
 
-// CheckValid 檢查 image 資訊是否有效
-func (i *SoftleaderHubImage) CheckValid() error {
+// ParseImage 解析 image 全名, e.g. hub.softleader.com.tw/team/app:1.2.3, 會忽略 docker 自動加上的 digest
+// 第一段不像 host 時 (不包含 '.' 或 ':' 且不為 localhost) 視為 docker hub 上的 image, 沒有 tag 時為 latest
+func ParseImage(s string) (*Image, error) {
+	if i := strings.Index(s, "@"); i >= 0 {
+		s = s[:i]
+	}
+	if strings.TrimSpace(s) == "" {
+		return nil, fmt.Errorf("image is required")
+	}
+	i := &Image{Tag: "latest"}
+	if c := strings.LastIndex(s, ":"); c > strings.LastIndex(s, "/") {
+		s, i.Tag = s[:c], s[c+1:]
+	}
+	parts := strings.Split(s, "/")
+	if first := parts[0]; len(parts) > 1 && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		i.Registry, parts = first, parts[1:]
+	} else {
+		i.Registry = "docker.io"
+	}
+	i.Namespace = strings.Join(parts[:len(parts)-1], "/")
+	i.Name = parts[len(parts)-1]
+	if i.Name == "" {
+		return nil, fmt.Errorf("invalid image %q", s)
+	}
+	return i, nil
+}
+
+// CheckValid 檢查 image 資訊是否有效, tag 是否為有效的版號由呼叫端依需求檢查
+func (i *Image) CheckValid() error {
 	if strings.TrimSpace(i.Name) == "" {
 		return fmt.Errorf("image name is required")
 	}
 	if strings.TrimSpace(i.Tag) == "" {
 		return fmt.Errorf("tag is required")
 	}
-	// GitHub 建議我們用 v 開頭, 但 v 開頭不符合 semver, 所以檢查時固定拿掉
-	v := strings.TrimPrefix(i.Tag, "v")
-	_, err := semver.Parse(v)
-	if err != nil {
-		return fmt.Errorf("requires valid semver2 tag: %s", err)
-	}
 	return nil
 }
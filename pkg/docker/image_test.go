@@ -0,0 +1,68 @@
+package docker
+
+import "testing"
+
+func TestImageString(t *testing.T) {
+	tests := []struct {
+		image    Image
+		expected string
+	}{
+		{Image{Name: "app", Tag: "1.0.0"}, "hub.softleader.com.tw/app:1.0.0"},
+		{Image{Namespace: "team", Name: "app", Tag: "1.0.0"}, "hub.softleader.com.tw/team/app:1.0.0"},
+		{Image{Registry: "registry.example.com:5000", Namespace: "/a/b/", Name: "app", Tag: "v1.0.0-0"}, "registry.example.com:5000/a/b/app:v1.0.0-0"},
+	}
+	for _, tt := range tests {
+		if actual := tt.image.String(); actual != tt.expected {
+			t.Errorf("expected %s, but got %s", tt.expected, actual)
+		}
+	}
+}
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected Image
+	}{
+		{"hub.softleader.com.tw/app:1.0.0@sha256:123", Image{Registry: "hub.softleader.com.tw", Name: "app", Tag: "1.0.0"}},
+		{"registry.example.com:5000/a/b/app:v1.0.0-0", Image{Registry: "registry.example.com:5000", Namespace: "a/b", Name: "app", Tag: "v1.0.0-0"}},
+		{"localhost/app", Image{Registry: "localhost", Name: "app", Tag: "latest"}},
+		{"softleader/app:1.0.0", Image{Registry: "docker.io", Namespace: "softleader", Name: "app", Tag: "1.0.0"}},
+	}
+	for _, tt := range tests {
+		actual, err := ParseImage(tt.image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *actual != tt.expected {
+			t.Errorf("expected %+v, but got %+v", tt.expected, *actual)
+		}
+	}
+	if _, err := ParseImage(""); err == nil {
+		t.Error("empty image should be invalid")
+	}
+}
+
+func TestSetPreRelease(t *testing.T) {
+	tests := []struct {
+		tag         string
+		identifiers []string
+		expected    string
+		valid       bool
+	}{
+		{"1.2.3", []string{"0"}, "1.2.3-0", true},
+		{"v1.2.3-0", []string{"2", "1"}, "v1.2.3-2.1", true},
+		{"1.2.3", []string{"do.not.use"}, "1.2.3", false},
+		{"1.2.3", []string{"01"}, "1.2.3", false},
+		{"latest", []string{"0"}, "latest", false},
+	}
+	for _, tt := range tests {
+		i := Image{Name: "app", Tag: tt.tag}
+		err := i.SetPreRelease(tt.identifiers...)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s %v: expected valid %v, but got %v", tt.tag, tt.identifiers, tt.valid, err)
+		}
+		if i.Tag != tt.expected {
+			t.Errorf("%s %v: expected tag %s, but got %s", tt.tag, tt.identifiers, tt.expected, i.Tag)
+		}
+	}
+}
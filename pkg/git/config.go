@@ -0,0 +1,367 @@
+package git
+
+import (
+	"fmt"
+	"github.com/mitchellh/go-homedir"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxIncludeDepth = 10
+)
+
+// Config 代表解析後的 git config, 保留了設定的先後順序
+type Config struct {
+	entries []entry
+}
+
+type entry struct {
+	section, subsection, key, value string
+}
+
+// Get 回傳最後一個設定的值, section 及 key 不分大小寫, subsection 有分大小寫
+func (c *Config) Get(section, subsection, key string) string {
+	values := c.GetAll(section, subsection, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// GetAll 依序回傳所有設定的值
+func (c *Config) GetAll(section, subsection, key string) (values []string) {
+	section, key = strings.ToLower(section), strings.ToLower(key)
+	for _, e := range c.entries {
+		if e.section == section && e.subsection == subsection && e.key == key {
+			values = append(values, e.value)
+		}
+	}
+	return
+}
+
+// Subsections 依序回傳 section 下所有的 subsection, e.g. 所有 remote 的名稱
+func (c *Config) Subsections(section string) (subsections []string) {
+	section = strings.ToLower(section)
+	found := make(map[string]bool)
+	for _, e := range c.entries {
+		if e.section == section && !found[e.subsection] {
+			found[e.subsection] = true
+			subsections = append(subsections, e.subsection)
+		}
+	}
+	return
+}
+
+// ParseConfig 解析 git config 格式的內容, 不處理 include
+func ParseConfig(b []byte) (*Config, error) {
+	c := &Config{}
+	if err := c.parse(b, nil); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadConfig 依序載入 global 及 gitDir 中的 config, 並展開 include 及 includeIf
+func LoadConfig(log *logrus.Logger, gitDir string) (*Config, error) {
+	c := &Config{}
+	var paths []string
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		paths = append(paths, filepath.Join(xdg, "git", "config"))
+	}
+	if home, err := homedir.Dir(); err == nil {
+		if os.Getenv("XDG_CONFIG_HOME") == "" {
+			paths = append(paths, filepath.Join(home, ".config", "git", "config"))
+		}
+		paths = append(paths, filepath.Join(home, ".gitconfig"))
+	}
+	if gitDir != "" {
+		paths = append(paths, filepath.Join(CommonDir(gitDir), "config"))
+	}
+	for _, p := range paths {
+		l := &loader{log: log, gitDir: gitDir, path: p}
+		if err := l.load(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// loader 負責載入單一 config 檔案, 並記錄 include 的相關資訊
+type loader struct {
+	log    *logrus.Logger
+	gitDir string
+	path   string
+	depth  int
+}
+
+func (l *loader) load(c *Config) error {
+	l.log.Debugf("loading git config: %s", l.path)
+	b, err := ioutil.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := c.parse(b, l); err != nil {
+		return fmt.Errorf("bad config file %s: %s", l.path, err)
+	}
+	return nil
+}
+
+// include 載入 include.path 或 includeIf.<condition>.path 所指定的檔案
+func (l *loader) include(c *Config, condition, path string) error {
+	if condition != "" && !l.matches(condition) {
+		return nil
+	}
+	if l.depth >= maxIncludeDepth {
+		return fmt.Errorf("exceeded maximum include depth (%d) while including %s", maxIncludeDepth, path)
+	}
+	path = l.expand(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(l.path), path)
+	}
+	included := &loader{log: l.log, gitDir: l.gitDir, path: path, depth: l.depth + 1}
+	return included.load(c)
+}
+
+func (l *loader) expand(path string) string {
+	if p, err := homedir.Expand(path); err == nil {
+		return p
+	}
+	return path
+}
+
+// matches 判斷 includeIf 的條件, 支援 gitdir:, gitdir/i: 及 onbranch:
+func (l *loader) matches(condition string) bool {
+	switch {
+	case strings.HasPrefix(condition, "gitdir:"):
+		return l.matchesGitDir(strings.TrimPrefix(condition, "gitdir:"), false)
+	case strings.HasPrefix(condition, "gitdir/i:"):
+		return l.matchesGitDir(strings.TrimPrefix(condition, "gitdir/i:"), true)
+	case strings.HasPrefix(condition, "onbranch:"):
+		pattern := strings.TrimPrefix(condition, "onbranch:")
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		b, err := ioutil.ReadFile(filepath.Join(l.gitDir, "HEAD"))
+		if err != nil {
+			return false
+		}
+		head := strings.TrimSpace(string(b))
+		if !strings.HasPrefix(head, "ref: refs/heads/") {
+			return false
+		}
+		return globMatch(pattern, strings.TrimPrefix(head, "ref: refs/heads/"), false)
+	}
+	return false
+}
+
+func (l *loader) matchesGitDir(pattern string, ignoreCase bool) bool {
+	if l.gitDir == "" {
+		return false
+	}
+	if strings.HasPrefix(pattern, "./") {
+		pattern = filepath.Join(filepath.Dir(l.path), pattern[2:])
+	} else {
+		pattern = l.expand(pattern)
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = "**/" + pattern
+	}
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	gitDir := filepath.ToSlash(l.gitDir)
+	return globMatch(filepath.ToSlash(pattern), gitDir, ignoreCase) ||
+		globMatch(filepath.ToSlash(pattern), gitDir+"/", ignoreCase)
+}
+
+// globMatch 以 wildmatch 的方式判斷 name 是否符合 pattern, '**' 可以跨越目錄
+func globMatch(pattern, name string, ignoreCase bool) bool {
+	var expr strings.Builder
+	if ignoreCase {
+		expr.WriteString("(?i)")
+	}
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case ch == '*':
+			expr.WriteString("[^/]*")
+		case ch == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	expr.WriteString("$")
+	r, err := regexp.Compile(expr.String())
+	if err != nil {
+		return false
+	}
+	return r.MatchString(name)
+}
+
+func (c *Config) parse(b []byte, l *loader) error {
+	var section, subsection string
+	lines := strings.Split(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n")
+	for n := 0; n < len(lines); n++ {
+		line := strings.TrimSpace(lines[n])
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			var err error
+			if section, subsection, err = parseSection(line); err != nil {
+				return fmt.Errorf("line %d: %s", n+1, err)
+			}
+			continue
+		}
+		if section == "" {
+			return fmt.Errorf("line %d: key outside of any section", n+1)
+		}
+		key, raw := line, ""
+		if i := strings.IndexByte(line, '='); i >= 0 {
+			key, raw = line[:i], line[i+1:]
+		} else if i := strings.IndexAny(line, "#;"); i >= 0 {
+			key = line[:i]
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value := "true" // 沒有 '=' 的 key 代表 boolean true
+		if strings.ContainsRune(line, '=') {
+			var continued bool
+			var err error
+			if value, continued, err = parseValue(raw, false); err != nil {
+				return fmt.Errorf("line %d: %s", n+1, err)
+			}
+			for continued && n+1 < len(lines) {
+				n++
+				var more string
+				if more, continued, err = parseValue(lines[n], true); err != nil {
+					return fmt.Errorf("line %d: %s", n+1, err)
+				}
+				value += more
+			}
+		}
+		c.entries = append(c.entries, entry{section: section, subsection: subsection, key: key, value: value})
+		if l != nil && key == "path" {
+			if section == "include" && subsection == "" {
+				if err := l.include(c, "", value); err != nil {
+					return err
+				}
+			}
+			if section == "includeif" {
+				if err := l.include(c, subsection, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseSection 解析 '[section]', '[section "subsection"]' 或舊式的 '[section.subsection]'
+func parseSection(line string) (section, subsection string, err error) {
+	i := 1
+	for i < len(line) && line[i] != ']' && line[i] != ' ' && line[i] != '\t' && line[i] != '"' {
+		i++
+	}
+	section = strings.ToLower(line[1:i])
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	if i >= len(line) {
+		return "", "", fmt.Errorf("unterminated section header: %s", line)
+	}
+	if line[i] == ']' {
+		if dot := strings.IndexByte(section, '.'); dot >= 0 {
+			section, subsection = section[:dot], section[dot+1:]
+		}
+		return section, subsection, nil
+	}
+	if line[i] != '"' {
+		return "", "", fmt.Errorf("invalid section header: %s", line)
+	}
+	var sub strings.Builder
+	for i++; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+			if i < len(line) {
+				sub.WriteByte(line[i])
+			}
+		case '"':
+			if i+1 >= len(line) || line[i+1] != ']' {
+				return "", "", fmt.Errorf("invalid section header: %s", line)
+			}
+			return section, sub.String(), nil
+		default:
+			sub.WriteByte(line[i])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated section header: %s", line)
+}
+
+// parseValue 解析 '=' 之後的值, 處理雙引號, 跳脫字元, 註解及行尾的 '\' 續行
+func parseValue(s string, continued bool) (value string, more bool, err error) {
+	var b strings.Builder
+	quoted := false
+	spaces := 0
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if !quoted && (ch == ' ' || ch == '\t') {
+			if b.Len() > 0 || continued {
+				spaces++
+			}
+			continue
+		}
+		if !quoted && (ch == '#' || ch == ';') {
+			break
+		}
+		if ch == '\\' && i+1 == len(s) {
+			b.WriteString(strings.Repeat(" ", spaces))
+			return b.String(), true, nil
+		}
+		b.WriteString(strings.Repeat(" ", spaces))
+		spaces = 0
+		switch ch {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			i++
+			switch s[i] {
+			case '\\', '"':
+				b.WriteByte(s[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				if b.Len() > 0 {
+					str := b.String()
+					b.Reset()
+					b.WriteString(str[:len(str)-1])
+				}
+			default:
+				return "", false, fmt.Errorf("bad escape sequence '\\%c'", s[i])
+			}
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	if quoted {
+		return "", false, fmt.Errorf("unterminated quoted value")
+	}
+	return b.String(), false, nil
+}
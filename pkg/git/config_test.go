@@ -0,0 +1,132 @@
+package git
+
+import (
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	config := `# comment
+[Core]
+	Bare = false ; trailing comment
+	filemode
+[remote "Origin"]
+	url = "https://github.com/softleader/s2i.git" # quoted
+[alias]
+	lg = log  --graph \
+	  --oneline
+	quote = "say \"hi\"\t"
+[Branch.Develop]
+	remote = origin`
+
+	c, err := ParseConfig([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		section, subsection, key, expected string
+	}{
+		{"core", "", "bare", "false"},
+		{"CORE", "", "FileMode", "true"},
+		{"remote", "Origin", "url", "https://github.com/softleader/s2i.git"},
+		{"remote", "origin", "url", ""},
+		{"alias", "", "lg", "log  --graph    --oneline"},
+		{"alias", "", "quote", "say \"hi\"\t"},
+		{"branch", "develop", "remote", "origin"},
+	}
+	for _, tt := range tests {
+		if v := c.Get(tt.section, tt.subsection, tt.key); v != tt.expected {
+			t.Errorf("%s.%s.%s should be %q, but got %q", tt.section, tt.subsection, tt.key, tt.expected, v)
+		}
+	}
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	for _, config := range []string{
+		"url = outside",
+		"[remote \"origin\"",
+		"[core]\n\tname = \"unterminated",
+		"[core]\n\tname = bad\\escape",
+	} {
+		if _, err := ParseConfig([]byte(config)); err == nil {
+			t.Errorf("should be error: %s", config)
+		}
+	}
+}
+
+func TestLoadConfigWithIncludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s2i-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+
+	gitDir := filepath.Join(dir, "work", "repo", ".git")
+	if err := os.MkdirAll(gitDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(gitDir, "config"), `[include]
+	path = ../../included
+[includeIf "gitdir:work/"]
+	path = ../../work.inc
+[includeIf "gitdir:other/"]
+	path = ../../other.inc`)
+	write(t, filepath.Join(dir, "work", "included"), `[remote "origin"]
+	url = git@github.com:softleader/s2i.git`)
+	write(t, filepath.Join(dir, "work", "work.inc"), `[user]
+	name = work`)
+	write(t, filepath.Join(dir, "work", "other.inc"), `[user]
+	name = other`)
+
+	c, err := LoadConfig(logrus.StandardLogger(), gitDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := c.Get("remote", "origin", "url"); v != "git@github.com:softleader/s2i.git" {
+		t.Errorf("url should be loaded from include.path, but got %q", v)
+	}
+	if v := c.Get("user", "", "name"); v != "work" {
+		t.Errorf("user.name should be loaded from includeIf gitdir, but got %q", v)
+	}
+}
+
+func TestFindGitDirFromGitFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s2i-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	worktreeGitDir := filepath.Join(dir, "main", ".git", "worktrees", "feature")
+	if err := os.MkdirAll(worktreeGitDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(worktreeGitDir, "commondir"), "../..")
+	src := filepath.Join(dir, "feature", "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(dir, "feature", ".git"), "gitdir: ../main/.git/worktrees/feature\n")
+
+	gitDir, err := FindGitDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gitDir != worktreeGitDir {
+		t.Fatalf("git dir should be %s, but got %s", worktreeGitDir, gitDir)
+	}
+	if common := CommonDir(gitDir); common != filepath.Join(dir, "main", ".git") {
+		t.Fatalf("common dir should be main/.git, but got %s", common)
+	}
+}
+
+func write(t *testing.T, path, content string) {
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	dotGit = ".git"
+)
+
+// FindGitDir 從 pwd 開始往上層找出 git dir, 支援 worktree 及 submodule 這類 .git 是檔案的情境
+func FindGitDir(pwd string) (string, error) {
+	dir, err := filepath.Abs(pwd)
+	if err != nil {
+		return "", err
+	}
+	for {
+		p := filepath.Join(dir, dotGit)
+		if fi, err := os.Stat(p); err == nil {
+			if fi.IsDir() {
+				return p, nil
+			}
+			return readGitFile(p)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("not a git repository (or any of the parent directories): %s", pwd)
+		}
+		dir = parent
+	}
+}
+
+// readGitFile 讀取內容為 'gitdir: <path>' 的 .git 檔案
+func readGitFile(p string) (string, error) {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	content := strings.TrimSpace(string(b))
+	if !strings.HasPrefix(content, "gitdir:") {
+		return "", fmt.Errorf("invalid gitfile format: %s", p)
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(content, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(p), gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// CommonDir 回傳 git dir 共用的目錄, 在 worktree 中 config, refs 等資料都放在 main worktree 的 git dir 中
+func CommonDir(gitDir string) string {
+	b, err := ioutil.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	dir := strings.TrimSpace(string(b))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
@@ -0,0 +1,159 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	refPrefix   = "ref: "
+	headsPrefix = "refs/heads/"
+	maxSymrefs  = 5
+)
+
+var (
+	sha = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	// 在 Jenkins 這類 detached HEAD 的 checkout 中, 我們試著從環境變數找回 branch 名稱
+	branchEnvs = []string{"BRANCH_NAME", "GIT_BRANCH", "GIT_LOCAL_BRANCH"}
+)
+
+// Head 代表當前 HEAD 的資訊
+type Head struct {
+	Branch   string // detached HEAD 且無法從環境變數判斷時為空
+	Commit   string // 完整的 commit SHA
+	Detached bool
+	Dirty    bool // working tree 是否有尚未 commit 的異動
+}
+
+// ResolveHead 從 pwd 所在的 git repository 解析出當前 branch 及 commit
+func ResolveHead(log *logrus.Logger, pwd string) (*Head, error) {
+	gitDir, err := FindGitDir(pwd)
+	if err != nil {
+		return nil, err
+	}
+	p := filepath.Join(gitDir, "HEAD")
+	log.Debugf("loading git HEAD: %s", p)
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	head := &Head{}
+	if head.Dirty, err = IsDirty(log, pwd); err != nil {
+		log.Debugf("unable to detect if the working tree is dirty: %s", err)
+	}
+	content := strings.TrimSpace(string(b))
+	if strings.HasPrefix(content, refPrefix) {
+		ref := strings.TrimPrefix(content, refPrefix)
+		head.Branch = strings.TrimPrefix(ref, headsPrefix)
+		// 剛 init 還沒有任何 commit 的 branch 是找不到 ref 的, 此時只回傳 branch
+		if head.Commit, err = ResolveRef(gitDir, ref); err != nil {
+			log.Debugln(err)
+		}
+		return head, nil
+	}
+	if !sha.MatchString(content) {
+		return nil, fmt.Errorf("invalid HEAD: %s", p)
+	}
+	head.Commit = content
+	head.Detached = true
+	head.Branch = branchFromEnv()
+	log.Debugf("HEAD is detached at %s, branch from env: %q", head.Commit, head.Branch)
+	return head, nil
+}
+
+func branchFromEnv() string {
+	for _, env := range branchEnvs {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			v = strings.TrimPrefix(v, headsPrefix)
+			if i := strings.IndexByte(v, '/'); i > 0 && env == "GIT_BRANCH" {
+				v = v[i+1:] // e.g. origin/develop
+			}
+			return v
+		}
+	}
+	return ""
+}
+
+// ResolveRef 依序從 loose refs 及 packed-refs 中找出 ref 對應的 commit SHA
+func ResolveRef(gitDir, ref string) (string, error) {
+	for i := 0; i < maxSymrefs; i++ {
+		target, err := readLooseRef(gitDir, ref)
+		if err != nil {
+			return "", err
+		}
+		if target == "" {
+			return readPackedRef(CommonDir(gitDir), ref)
+		}
+		if !strings.HasPrefix(target, refPrefix) {
+			return target, nil
+		}
+		ref = strings.TrimPrefix(target, refPrefix)
+	}
+	return "", fmt.Errorf("too many levels of symbolic refs: %s", ref)
+}
+
+// readLooseRef 讀取 ref 檔案, worktree 自己的 ref 放在 git dir, 其餘的放在 common dir
+func readLooseRef(gitDir, ref string) (string, error) {
+	for _, dir := range []string{gitDir, CommonDir(gitDir)} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err == nil {
+			return strings.TrimSpace(string(b)), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+func readPackedRef(commonDir, ref string) (string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(commonDir, "packed-refs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("ref not found: %s", ref)
+		}
+		return "", err
+	}
+	return findPackedRef(b, ref)
+}
+
+func findPackedRef(b []byte, ref string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := scanner.Text()
+		// '#' 開頭為 header, '^' 開頭為上一行 annotated tag 所指向的 commit
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("ref not found: %s", ref)
+}
+
+// IsDirty 判斷 working tree 是否有尚未 commit 的異動 (不含 untracked files)
+func IsDirty(log *logrus.Logger, pwd string) (bool, error) {
+	cmd := exec.Command("git", "status", "--porcelain", "--untracked-files=no")
+	cmd.Dir = pwd
+	if log.IsLevelEnabled(logrus.DebugLevel) {
+		log.Out.Write([]byte(fmt.Sprintln(strings.Join(cmd.Args, " "))))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+	return len(bytes.TrimSpace(out)) > 0, nil
+}
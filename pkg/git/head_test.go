@@ -0,0 +1,67 @@
+package git
+
+import (
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindPackedRef(t *testing.T) {
+	packed := []byte(`# pack-refs with: peeled fully-peeled sorted 
+0d4a5f0d2b7e9bb1c6b0cd4bb5fb6f3cbf1d4a8e refs/heads/develop
+6f1e2c1e5b3e4a9fc0ddc2e0e57f0e43a7b7c112 refs/tags/1.0.0
+^a7b0e1b5b1f2f0cbd1e2c3f4a5b6c7d8e9f0a1b2
+`)
+	commit, err := findPackedRef(packed, "refs/heads/develop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit != "0d4a5f0d2b7e9bb1c6b0cd4bb5fb6f3cbf1d4a8e" {
+		t.Fatalf("commit should be 0d4a5f0d2b7e9bb1c6b0cd4bb5fb6f3cbf1d4a8e, but got %q", commit)
+	}
+	if _, err := findPackedRef(packed, "refs/heads/master"); err == nil {
+		t.Fatal("should be error if ref not found")
+	}
+}
+
+func TestResolveHead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s2i-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	gitDir := filepath.Join(dir, ".git")
+	if err := os.MkdirAll(filepath.Join(gitDir, "refs", "heads"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(gitDir, "packed-refs"), "0d4a5f0d2b7e9bb1c6b0cd4bb5fb6f3cbf1d4a8e refs/heads/develop\n")
+
+	write(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/develop\n")
+	head, err := ResolveHead(logrus.StandardLogger(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Branch != "develop" || head.Commit != "0d4a5f0d2b7e9bb1c6b0cd4bb5fb6f3cbf1d4a8e" || head.Detached {
+		t.Fatalf("should resolve develop from packed-refs, but got %+v", head)
+	}
+
+	write(t, filepath.Join(gitDir, "refs", "heads", "develop"), "6f1e2c1e5b3e4a9fc0ddc2e0e57f0e43a7b7c112\n")
+	if head, err = ResolveHead(logrus.StandardLogger(), dir); err != nil {
+		t.Fatal(err)
+	}
+	if head.Commit != "6f1e2c1e5b3e4a9fc0ddc2e0e57f0e43a7b7c112" {
+		t.Fatalf("loose ref should take precedence over packed-refs, but got %q", head.Commit)
+	}
+
+	os.Setenv("GIT_BRANCH", "origin/feature/x")
+	defer os.Unsetenv("GIT_BRANCH")
+	write(t, filepath.Join(gitDir, "HEAD"), "6f1e2c1e5b3e4a9fc0ddc2e0e57f0e43a7b7c112\n")
+	if head, err = ResolveHead(logrus.StandardLogger(), dir); err != nil {
+		t.Fatal(err)
+	}
+	if !head.Detached || head.Branch != "feature/x" || head.Commit != "6f1e2c1e5b3e4a9fc0ddc2e0e57f0e43a7b7c112" {
+		t.Fatalf("should resolve detached HEAD with branch from $GIT_BRANCH, but got %+v", head)
+	}
+}
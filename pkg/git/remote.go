@@ -0,0 +1,114 @@
+package git
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultRemote 是 git 預設的 remote 名稱
+	DefaultRemote = "origin"
+)
+
+// Remote 代表 git remote 解析後的資訊
+type Remote struct {
+	Name  string
+	URL   string // 經過 insteadOf 改寫後的 url
+	Host  string
+	Owner string // 可能包含多層的 group, e.g. GitLab 的 group/subgroup
+	Repo  string
+	// Credential 是 clone 時嵌入在 url 中的 token 或密碼
+	Credential string
+}
+
+// FindRemote 從 pwd 所在的 git repository 中找出指定名稱的 remote
+func FindRemote(log *logrus.Logger, pwd, name string) (*Remote, error) {
+	gitDir, err := FindGitDir(pwd)
+	if err != nil {
+		return nil, err
+	}
+	config, err := LoadConfig(log, gitDir)
+	if err != nil {
+		return nil, err
+	}
+	return config.Remote(name)
+}
+
+// Remote 回傳指定名稱的 remote, url 會先經過 'url.<base>.insteadOf' 改寫
+func (c *Config) Remote(name string) (*Remote, error) {
+	if name == "" {
+		name = DefaultRemote
+	}
+	raw := c.Get("remote", name, "url")
+	if raw == "" {
+		return nil, fmt.Errorf("remote %q not found, available remotes: %v", name, c.Subsections("remote"))
+	}
+	r, err := ParseURL(c.RewriteURL(raw))
+	if err != nil {
+		return nil, err
+	}
+	r.Name = name
+	return r, nil
+}
+
+// RewriteURL 以最長符合的 'url.<base>.insteadOf' 改寫 url
+func (c *Config) RewriteURL(raw string) string {
+	var base, prefix string
+	for _, e := range c.entries {
+		if e.section != "url" || e.key != "insteadof" {
+			continue
+		}
+		if strings.HasPrefix(raw, e.value) && len(e.value) > len(prefix) {
+			base, prefix = e.subsection, e.value
+		}
+	}
+	if prefix == "" {
+		return raw
+	}
+	return base + strings.TrimPrefix(raw, prefix)
+}
+
+// ParseURL 解析 git remote url, 支援:
+//
+//	https://[user[:password]@]host[:port]/owner/repo[.git]
+//	ssh://[user@]host[:port]/owner/repo[.git]
+//	git://host[:port]/owner/repo[.git]
+//	[user@]host:owner/repo[.git]
+func ParseURL(raw string) (*Remote, error) {
+	r := &Remote{URL: raw}
+	var path string
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote url %q: %s", raw, err)
+		}
+		r.Host = u.Hostname()
+		if u.User != nil && (u.Scheme == "http" || u.Scheme == "https") {
+			if password, set := u.User.Password(); set {
+				r.Credential = password
+			} else {
+				r.Credential = u.User.Username()
+			}
+		}
+		path = u.Path
+	} else {
+		colon := strings.IndexByte(raw, ':')
+		if colon < 0 || strings.ContainsRune(raw[:colon], '/') {
+			return nil, fmt.Errorf("unsupported remote url %q, local path is not supported", raw)
+		}
+		r.Host = raw[:colon]
+		if at := strings.LastIndexByte(r.Host, '@'); at >= 0 {
+			r.Host = r.Host[at+1:]
+		}
+		path = raw[colon+1:]
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	slash := strings.LastIndexByte(path, '/')
+	if r.Host == "" || slash <= 0 || slash == len(path)-1 {
+		return nil, fmt.Errorf("unable to find host, owner and repo in remote url %q", raw)
+	}
+	r.Owner, r.Repo = path[:slash], path[slash+1:]
+	return r, nil
+}
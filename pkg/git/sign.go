@@ -0,0 +1,67 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os/exec"
+	"strings"
+)
+
+const (
+	defaultGPGProgram = "gpg"
+)
+
+// Signer 依照 git config 的設定簽署 tag, 與 'git tag -s' 使用相同的設定
+type Signer struct {
+	log *logrus.Logger
+	// Program 為 gpg 執行檔, 對應 gpg.program
+	Program string
+	// Key 為簽署的 key, 對應 user.signingkey, 沒有設定時與 git 相同使用 'name <email>'
+	Key string
+}
+
+// NewSigner 從 git config 建立 Signer, key 不為空時優先使用
+func NewSigner(log *logrus.Logger, c *Config, key string) *Signer {
+	s := &Signer{
+		log:     log,
+		Program: c.Get("gpg", "", "program"),
+		Key:     key,
+	}
+	if s.Program == "" {
+		s.Program = defaultGPGProgram
+	}
+	if s.Key == "" {
+		s.Key = c.Get("user", "", "signingkey")
+	}
+	if s.Key == "" {
+		name, email := c.User()
+		s.Key = fmt.Sprintf("%s <%s>", name, email)
+	}
+	return s
+}
+
+// Sign 產生 payload 的 armored detach 簽章
+func (s *Signer) Sign(payload []byte) ([]byte, error) {
+	cmd := exec.Command(s.Program, "--status-fd=2", "-bsau", s.Key)
+	if s.log.IsLevelEnabled(logrus.DebugLevel) {
+		s.log.Out.Write([]byte(fmt.Sprintln(strings.Join(cmd.Args, " "))))
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	// 與 git 相同, 以 gpg 的 status 確認確實產生了簽章
+	if !strings.Contains(stderr.String(), "[GNUPG:] SIG_CREATED ") {
+		return nil, fmt.Errorf("gpg failed to sign the data: %s", strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// User 回傳 user.name 及 user.email
+func (c *Config) User() (name, email string) {
+	return c.Get("user", "", "name"), c.Get("user", "", "email")
+}
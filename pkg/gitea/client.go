@@ -0,0 +1,113 @@
+package gitea
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/softleader/s2i/pkg/scm/restapi"
+	"gopkg.in/resty.v1"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pathRepo = "/repos/%s/%s"
+	limit    = 50
+)
+
+var _ scm.SCM = &Client{}
+
+// Client 封裝了跟 Gitea 上某個 repo 互動的 api, 實作了 scm.SCM
+type Client struct {
+	c    *resty.Client
+	log  *logrus.Logger
+	repo string
+}
+
+// NewClient 建立跟 Gitea 互動的 client, baseURL 傳入 Gitea 的網址, e.g. https://gitea.example.com
+func NewClient(log *logrus.Logger, baseURL, token, owner, repo string) *Client {
+	c := restapi.NewClient(log, strings.TrimSuffix(baseURL, "/")+"/api/v1")
+	if token != "" {
+		c.SetHeader("Authorization", "token "+token)
+	}
+	return &Client{
+		log:  log,
+		repo: fmt.Sprintf(pathRepo, url.PathEscape(owner), url.PathEscape(repo)),
+		c:    c,
+	}
+}
+
+// newError 將非 2xx 的 response 轉換成 error, 404 時回傳 scm.ErrNotFound
+func newError(resp *resty.Response) error {
+	return restapi.NewError("gitea", resp)
+}
+
+// get 依序取得每一頁的資料並交給 fn 處理, fn 回傳該頁的筆數, 筆數少於 limit 代表已是最後一頁
+func (c *Client) get(path string, fn func(body []byte) (int, error)) error {
+	for page := 1; ; page++ {
+		c.log.Debugf("fetching page %v of %s", page, path)
+		resp, err := c.c.R().
+			SetQueryParams(map[string]string{"page": strconv.Itoa(page), "limit": strconv.Itoa(limit)}).
+			Get(path)
+		if err != nil {
+			return err
+		}
+		if !resp.IsSuccess() {
+			return newError(resp)
+		}
+		n, err := fn(resp.Body())
+		if err != nil {
+			return err
+		}
+		if n < limit {
+			return nil
+		}
+	}
+}
+
+// CommitExists 判斷 commit 是否已經 push 到 Gitea 上
+func (c *Client) CommitExists(sha string) (bool, error) {
+	c.log.Debugf("checking if commit %s exists on %s", sha, c.repo)
+	resp, err := c.c.R().Get(c.repo + "/git/commits/" + url.PathEscape(sha))
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode() == 404 || resp.StatusCode() == 422 {
+		return false, nil
+	}
+	if !resp.IsSuccess() {
+		return false, newError(resp)
+	}
+	return true, nil
+}
+
+type release struct {
+	ID              int64     `json:"id"`
+	TagName         string    `json:"tag_name"`
+	TargetCommitish string    `json:"target_commitish"`
+	Name            string    `json:"name"`
+	Body            string    `json:"body"`
+	Draft           bool      `json:"draft"`
+	Prerelease      bool      `json:"prerelease"`
+	PublishedAt     time.Time `json:"published_at"`
+	HTMLURL         string    `json:"html_url"`
+	Author          struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+func (r *release) toRelease() *scm.Release {
+	return &scm.Release{
+		TagName:         r.TagName,
+		TargetCommitish: r.TargetCommitish,
+		Name:            r.Name,
+		Body:            r.Body,
+		Draft:           r.Draft,
+		Prerelease:      r.Prerelease,
+		PublishedAt:     r.PublishedAt,
+		HTMLURL:         r.HTMLURL,
+		Author:          r.Author.Login,
+	}
+}
@@ -0,0 +1,127 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/softleader/s2i/pkg/scm"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+var (
+	// Gitea merge 及 squash merge 預設的 commit message 都會帶有 PR 編號
+	pullRequestRefs = []*regexp.Regexp{
+		regexp.MustCompile(`^Merge pull request '.*' \(#(\d+)\)`),
+		regexp.MustCompile(`^[^\n]*\(#(\d+)\)[ \t]*(?:\n|$)`),
+	}
+)
+
+// Changes 回傳 base 到 head 之間的 commits 及已 merge 的 pull requests
+func (c *Client) Changes(base, head string) (commits []*scm.Commit, pulls []*scm.PullRequest, err error) {
+	c.log.Debugf("comparing %s...%s of %s", base, head, c.repo)
+	resp, err := c.c.R().Get(c.repo + "/compare/" + url.PathEscape(base) + "..." + url.PathEscape(head))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, nil, newError(resp)
+	}
+	var cmp struct {
+		Commits []commit `json:"commits"`
+	}
+	if err := json.Unmarshal(resp.Body(), &cmp); err != nil {
+		return nil, nil, err
+	}
+	for _, cc := range cmp.Commits {
+		commits = append(commits, cc.toCommit())
+	}
+	for _, n := range scm.PullRequestNumbers(commits, pullRequestRefs...) {
+		pr, err := c.pullRequest(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pr != nil {
+			pulls = append(pulls, pr)
+		}
+	}
+	return
+}
+
+type commit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+func (c *commit) toCommit() *scm.Commit {
+	author := c.Commit.Author.Name
+	if c.Author != nil && c.Author.Login != "" {
+		author = c.Author.Login
+	}
+	return &scm.Commit{
+		SHA:     c.SHA,
+		Message: c.Commit.Message,
+		Author:  author,
+	}
+}
+
+// pullRequest 回傳已 merge 的 pull request 及其 commits, 不存在或尚未 merge 時回傳 nil
+func (c *Client) pullRequest(number int) (*scm.PullRequest, error) {
+	c.log.Debugf("fetching pull request #%d", number)
+	path := fmt.Sprintf("%s/pulls/%d", c.repo, number)
+	resp, err := c.c.R().Get(path)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		if err := newError(resp); err != scm.ErrNotFound {
+			return nil, err
+		}
+		return nil, nil
+	}
+	var pr struct {
+		Title          string     `json:"title"`
+		MergedAt       *time.Time `json:"merged_at"`
+		MergeCommitSHA string     `json:"merge_commit_sha"`
+		Labels         []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(resp.Body(), &pr); err != nil {
+		return nil, err
+	}
+	if pr.MergedAt == nil {
+		return nil, nil
+	}
+	p := &scm.PullRequest{
+		Number:      number,
+		Title:       pr.Title,
+		Author:      pr.User.Login,
+		MergeCommit: pr.MergeCommitSHA,
+	}
+	for _, l := range pr.Labels {
+		p.Labels = append(p.Labels, l.Name)
+	}
+	err = c.get(path+"/commits", func(body []byte) (int, error) {
+		var cs []commit
+		if err := json.Unmarshal(body, &cs); err != nil {
+			return 0, err
+		}
+		for _, c := range cs {
+			p.Commits = append(p.Commits, c.SHA)
+		}
+		return len(cs), nil
+	})
+	return p, err
+}
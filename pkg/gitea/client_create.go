@@ -0,0 +1,81 @@
+package gitea
+
+import (
+	"encoding/json"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/softleader/s2i/pkg/scm/restapi"
+)
+
+// CreateRelease 建立 Gitea 的 release
+func (c *Client) CreateRelease(commitish, tag string, notes *scm.Notes) (*scm.Release, error) {
+	c.log.Debugf("creating release %s for %s commitish: %s", tag, c.repo, commitish)
+	r, err := c.createRelease(commitish, tag, notes, false, false)
+	if err != nil {
+		return nil, err
+	}
+	c.log.Printf("Successfully created release: %s", r.HTMLURL)
+	return r, nil
+}
+
+// CreatePrerelease 建立 Gitea 的 pre-release
+func (c *Client) CreatePrerelease(commitish, tag string, notes *scm.Notes, force bool) (*scm.Release, error) {
+	c.log.Debugf("creating pre-release %s for %s commitish: %s", tag, c.repo, commitish)
+	r, err := c.createRelease(commitish, tag, notes, true, false)
+	if err != nil {
+		giteaErr, ok := err.(*restapi.Error)
+		if !ok {
+			return nil, err
+		}
+		if force && giteaErr.StatusCode == 409 { // 代表 release 已存在
+			c.log.Debugf("tag name %s already exists, force to delete it..", tag)
+			if err := c.DeleteReleaseAndTag(tag, false); err != nil {
+				return nil, err
+			}
+		}
+		c.log.Debugf("creating pre-release %s again for %s commitish: %s", tag, c.repo, commitish)
+		if r, err = c.createRelease(commitish, tag, notes, true, false); err != nil {
+			return nil, err
+		}
+	}
+	c.log.Printf("Successfully created pre-release: %s", r.HTMLURL)
+	return r, nil
+}
+
+// CreateDraftRelease 建立 Gitea 的 draft release
+func (c *Client) CreateDraftRelease(commitish, tag string, notes *scm.Notes, prerelease bool) (*scm.Release, error) {
+	c.log.Debugf("creating draft release %s for %s commitish: %s", tag, c.repo, commitish)
+	r, err := c.createRelease(commitish, tag, notes, prerelease, true)
+	if err != nil {
+		return nil, err
+	}
+	c.log.Printf("Successfully created draft release: %s", r.HTMLURL)
+	return r, nil
+}
+
+func (c *Client) createRelease(commitish, tag string, notes *scm.Notes, prerelease, draft bool) (*scm.Release, error) {
+	body := map[string]interface{}{
+		"tag_name":         tag,
+		"target_commitish": commitish,
+		"prerelease":       prerelease,
+		"draft":            draft,
+	}
+	if notes != nil {
+		body["name"] = notes.Name
+		body["body"] = notes.Body
+	}
+	resp, err := c.c.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).
+		Post(c.repo + "/releases")
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, newError(resp)
+	}
+	var r release
+	if err := json.Unmarshal(resp.Body(), &r); err != nil {
+		return nil, err
+	}
+	return r.toRelease(), nil
+}
@@ -0,0 +1,64 @@
+package gitea
+
+import (
+	"fmt"
+	"github.com/softleader/s2i/pkg/scm"
+	"net/url"
+)
+
+// DeleteReleaseAndTag 刪除 release 及其 tag, release 及 tag 都不存在時回傳 scm.ErrNotFound
+func (c *Client) DeleteReleaseAndTag(tag string, dryRun bool) error {
+	releaseDeleted, err := c.deleteRelease(tag, dryRun)
+	if err != nil {
+		return err
+	}
+	tagDeleted, err := c.deleteTag(tag, dryRun)
+	if err != nil {
+		return err
+	}
+	if !releaseDeleted && !tagDeleted {
+		return scm.ErrNotFound
+	}
+	return nil
+}
+
+func (c *Client) deleteRelease(tag string, dryRun bool) (bool, error) {
+	c.log.Debugf("fetching release-id of tag '%s'", tag)
+	r, err := c.getRelease(tag)
+	if err == scm.ErrNotFound { // 代表 release 不存在, 直接中斷不丟錯
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	c.log.Debugf("deleting release %s by release-id %d", tag, r.ID)
+	if dryRun {
+		return true, nil
+	}
+	resp, err := c.c.R().Delete(fmt.Sprintf("%s/releases/%d", c.repo, r.ID))
+	if err != nil {
+		return false, err
+	}
+	if !resp.IsSuccess() {
+		return false, newError(resp)
+	}
+	return true, nil
+}
+
+func (c *Client) deleteTag(tag string, dryRun bool) (bool, error) {
+	c.log.Debugf("deleting tag %s", tag)
+	if dryRun {
+		return true, nil
+	}
+	resp, err := c.c.R().Delete(c.repo + "/tags/" + url.PathEscape(tag))
+	if err != nil {
+		return false, err
+	}
+	if !resp.IsSuccess() {
+		if err := newError(resp); err != scm.ErrNotFound { // 代表 tag 不存在, 直接中斷不丟錯
+			return false, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package gitea
+
+import (
+	"encoding/json"
+	"github.com/softleader/s2i/pkg/scm"
+	"net/url"
+)
+
+// ListReleases 列出所有的 release
+func (c *Client) ListReleases() (releases []*scm.Release, err error) {
+	err = c.get(c.repo+"/releases", func(body []byte) (int, error) {
+		var rs []release
+		if err := json.Unmarshal(body, &rs); err != nil {
+			return 0, err
+		}
+		for _, r := range rs {
+			releases = append(releases, r.toRelease())
+		}
+		return len(rs), nil
+	})
+	return
+}
+
+// LatestRelease 回傳最新一版非 pre-release 的 release
+func (c *Client) LatestRelease() (*scm.Release, error) {
+	c.log.Debugf("fetching latest release of %s", c.repo)
+	releases, err := c.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range releases {
+		if !r.Prerelease && !r.Draft {
+			return r, nil
+		}
+	}
+	return nil, scm.ErrNotFound
+}
+
+// GetRelease 回傳 tag 的 release
+func (c *Client) GetRelease(tag string) (*scm.Release, error) {
+	r, err := c.getRelease(tag)
+	if err != nil {
+		return nil, err
+	}
+	return r.toRelease(), nil
+}
+
+func (c *Client) getRelease(tag string) (*release, error) {
+	resp, err := c.c.R().Get(c.repo + "/releases/tags/" + url.PathEscape(tag))
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, newError(resp)
+	}
+	var r release
+	if err := json.Unmarshal(resp.Body(), &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListTags 列出所有的 tag 名稱
+func (c *Client) ListTags() (tags []string, err error) {
+	err = c.get(c.repo+"/tags", func(body []byte) (int, error) {
+		var ts []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(body, &ts); err != nil {
+			return 0, err
+		}
+		for _, t := range ts {
+			tags = append(tags, t.Name)
+		}
+		return len(ts), nil
+	})
+	return
+}
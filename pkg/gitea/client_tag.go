@@ -0,0 +1,156 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/softleader/s2i/pkg/scm"
+	"net/url"
+)
+
+type gitTag struct {
+	Message string `json:"message"`
+	Commit  struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// ResolveTag 回傳 tag 指向的 commit SHA
+func (c *Client) ResolveTag(tag string) (string, error) {
+	c.log.Debugf("resolving tag %s of %s", tag, c.repo)
+	t, err := c.getTag(tag)
+	if err != nil {
+		return "", err
+	}
+	return t.Commit.SHA, nil
+}
+
+// TagMessage 回傳 annotated tag 的 message, lightweight tag 回傳空字串
+func (c *Client) TagMessage(tag string) (string, error) {
+	t, err := c.getTag(tag)
+	if err != nil {
+		return "", err
+	}
+	return t.Message, nil
+}
+
+func (c *Client) getTag(tag string) (*gitTag, error) {
+	resp, err := c.c.R().Get(c.repo + "/tags/" + url.PathEscape(tag))
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, newError(resp)
+	}
+	t := &gitTag{}
+	if err := json.Unmarshal(resp.Body(), t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// CreateTag 在 commitish 上建立 tag, 有 message 時建立 annotated tag, Gitea 的 api 不支援簽署 tag
+func (c *Client) CreateTag(commitish, tag string, opts *scm.TagOptions) error {
+	if opts.Signed() {
+		return fmt.Errorf("signed tag is not supported on Gitea, please sign and push the tag with git instead")
+	}
+	body := map[string]string{
+		"tag_name": tag,
+		"target":   commitish,
+	}
+	if opts.Annotated() {
+		body["message"] = opts.Message
+	}
+	c.log.Debugf("creating tag %s on %s for %s", tag, commitish, c.repo)
+	resp, err := c.c.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).
+		Post(c.repo + "/tags")
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return newError(resp)
+	}
+	return nil
+}
+
+// MoveTag 將已存在的 tag 移到 commitish 上, Gitea 沒有更新 tag 的 api, 因此會先刪除再重新建立;
+// 為了避免連同 release 一起被刪除, tag 已有 release 時會回傳錯誤, 重新建立失敗時會將 tag 還原到原本的 commit
+func (c *Client) MoveTag(tag, commitish string, opts *scm.TagOptions) error {
+	if opts.Signed() {
+		return fmt.Errorf("signed tag is not supported on Gitea, please sign and push the tag with git instead")
+	}
+	old, err := c.getTag(tag)
+	if err != nil {
+		return err
+	}
+	if _, err := c.GetRelease(tag); err == nil {
+		return fmt.Errorf("tag %s has a release on Gitea, moving it would delete the release", tag)
+	} else if err != scm.ErrNotFound {
+		return err
+	}
+	sha, err := c.resolveCommit(commitish)
+	if err != nil {
+		return err
+	}
+	c.log.Debugf("moving tag %s to %s for %s", tag, sha, c.repo)
+	if _, err := c.deleteTag(tag, false); err != nil {
+		return err
+	}
+	if err := c.CreateTag(sha, tag, opts); err != nil {
+		return c.restoreTag(tag, old, err)
+	}
+	return nil
+}
+
+// restoreTag 在移動 tag 失敗後, 以原本的 message 將 tag 重新建立在原本的 commit 上
+func (c *Client) restoreTag(tag string, old *gitTag, cause error) error {
+	c.log.Debugf("restoring tag %s on %s for %s", tag, old.Commit.SHA, c.repo)
+	var opts *scm.TagOptions
+	if old.Message != "" {
+		opts = &scm.TagOptions{Message: old.Message}
+	}
+	if err := c.CreateTag(old.Commit.SHA, tag, opts); err != nil {
+		return fmt.Errorf("failed to move tag %s: %s, and failed to restore it on %s: %s", tag, cause, old.Commit.SHA, err)
+	}
+	return fmt.Errorf("failed to move tag %s, it has been restored on %s: %s", tag, old.Commit.SHA, cause)
+}
+
+// resolveCommit 回傳 commitish (branch 或 commit SHA) 指向的 commit SHA, 不存在時回傳 *scm.RefNotFoundError;
+// 舊版 Gitea 的 git/commits 只接受 commit SHA, 因此找不到時會再以 branch 查詢
+func (c *Client) resolveCommit(commitish string) (string, error) {
+	resp, err := c.c.R().Get(c.repo + "/git/commits/" + url.PathEscape(commitish))
+	if err != nil {
+		return "", err
+	}
+	if resp.IsSuccess() {
+		var commit struct {
+			SHA string `json:"sha"`
+		}
+		if err := json.Unmarshal(resp.Body(), &commit); err != nil {
+			return "", err
+		}
+		return commit.SHA, nil
+	}
+	if code := resp.StatusCode(); code != 404 && code != 422 {
+		return "", newError(resp)
+	}
+	if resp, err = c.c.R().Get(c.repo + "/branches/" + url.PathEscape(commitish)); err != nil {
+		return "", err
+	}
+	if resp.StatusCode() == 404 {
+		return "", &scm.RefNotFoundError{Ref: commitish}
+	}
+	if !resp.IsSuccess() {
+		return "", newError(resp)
+	}
+	var branch struct {
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	if err := json.Unmarshal(resp.Body(), &branch); err != nil {
+		return "", err
+	}
+	return branch.Commit.ID, nil
+}
@@ -3,102 +3,115 @@ package github
 import (
 	"context"
 	"fmt"
-	"github.com/blang/semver"
 	"github.com/google/go-github/v28/github"
 	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
 	"golang.org/x/oauth2"
-	"io/ioutil"
-	"path/filepath"
-	"regexp"
+	"net/url"
 	"strings"
 )
 
-var (
-	r = regexp.MustCompile(`\[remote "origin"\][\n|\r|\n\r|\t|\s]+url = [https://|git@]+([^@]+)?@?github.com[/:](.+)/(.+).git`)
+const (
+	// Host 是公開的 GitHub 網域
+	Host = "github.com"
 )
 
+var _ scm.SCM = &Client{}
+
+// Client 封裝了跟 GitHub 上某個 repo 互動的 api, 實作了 scm.SCM
+type Client struct {
+	log   *logrus.Logger
+	ctx   context.Context
+	c     *github.Client
+	owner string
+	repo  string
+
+	throttle *throttle
+}
+
+// NewClient 建立跟 GitHub 互動的 client, baseURL 傳入 GitHub Enterprise 的網址, e.g. https://github.example.com/, 空字串代表使用 github.com
+func NewClient(log *logrus.Logger, baseURL, token, owner, repo string) (*Client, error) {
+	ctx := context.Background()
+	c, err := newTokenClient(ctx, baseURL, token)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		log:   log,
+		ctx:   ctx,
+		c:     c,
+		owner: owner,
+		repo:  repo,
+
+		throttle: &throttle{},
+	}, nil
+}
+
 // NewTokenClient 建立跟 github 互動的 client
-func newTokenClient(ctx context.Context, token string) (*github.Client, error) {
+func newTokenClient(ctx context.Context, baseURL, token string) (*github.Client, error) {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
-	return github.NewClient(tc), nil
-}
-
-// FindNextReleaseVersion 找下一版 revision,  也就是 latest release + 1 版本號
-func FindNextReleaseVersion(log *logrus.Logger, token, owner, repo string) (string, error) {
-	if token == "" || owner == "" || repo == "" {
-		return "", nil
+	if baseURL == "" {
+		return github.NewClient(tc), nil
 	}
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
-	if err != nil {
-		return "", err
-	}
-	log.Debugf("fetching latest release of %s/%s", owner, repo)
-	rr, _, err := client.Repositories.GetLatestRelease(ctx, owner, repo)
+	api, upload, err := enterpriseURLs(baseURL)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	tag := rr.GetTagName()
-	log.Debugf("found %s drafted by %s published at %s", tag, rr.GetAuthor().GetLogin(), rr.GetPublishedAt())
-	version := strings.TrimPrefix(tag, "v")
-	sv, err := semver.Parse(version)
+	return github.NewEnterpriseClient(api, upload, tc)
+}
+
+// enterpriseURLs 回傳 GitHub Enterprise 的 API 及 upload 網址
+func enterpriseURLs(base string) (api, upload string, err error) {
+	u, err := url.Parse(base)
 	if err != nil {
-		return "", err
+		return "", "", fmt.Errorf("invalid github url %q: %s", base, err)
 	}
-	bumpPatch(&sv)
-	next := sv.String()
-	if strings.HasPrefix(tag, "v") {
-		next = "v" + next
+	if u.Scheme == "" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid github url %q: requires scheme and host, e.g. https://github.example.com", base)
 	}
-	return next, nil
-}
-
-func bumpPatch(sv *semver.Version) {
-	sv.Patch++
-	sv.Pre = nil
-	sv.Build = nil
+	path := strings.TrimSuffix(u.Path, "/")
+	path = strings.TrimSuffix(path, "/api/v3")
+	path = strings.TrimSuffix(path, "/api/uploads")
+	u.Path = path + "/api/v3/"
+	api = u.String()
+	u.Path = path + "/api/uploads/"
+	upload = u.String()
+	return
 }
 
-// Remote 回傳從 .git 中找到的 token, owner and repo
-func Remote(log *logrus.Logger, pwd string) (token, owner, repo string) {
-	p := filepath.Join(pwd, ".git", "config")
-	log.Debugf("loading git config: %s", p)
-	b, err := ioutil.ReadFile(p)
+// LatestRelease 回傳最新一版非 pre-release 的 release
+func (c *Client) LatestRelease() (*scm.Release, error) {
+	c.log.Debugf("fetching latest release of %s/%s", c.owner, c.repo)
+	rr, _, err := c.c.Repositories.GetLatestRelease(c.ctx, c.owner, c.repo)
 	if err != nil {
-		return
+		return nil, notFound(err)
 	}
-	config := string(b)
-	return findRemoteOrigin(log, config)
+	return newRelease(rr), nil
 }
 
-func findRemoteOrigin(log *logrus.Logger, config string) (token, owner, repo string) {
-	groups := r.FindStringSubmatch(config)
-	log.Debugf("found %d remote origin", len(groups)-1)
-	if len(groups) < 1 {
-		return
+// CommitExists 判斷 commit 是否已經 push 到 GitHub 上
+func (c *Client) CommitExists(sha string) (bool, error) {
+	c.log.Debugf("checking if commit %s exists on %s/%s", sha, c.owner, c.repo)
+	if _, _, err := c.c.Repositories.GetCommitSHA1(c.ctx, c.owner, c.repo, sha, ""); err != nil {
+		githubErr, ok := err.(*github.ErrorResponse)
+		if !ok {
+			return false, err
+		}
+		if code := githubErr.Response.StatusCode; code == 404 || code == 422 { // 代表 commit 不存在於 GitHub 上
+			return false, nil
+		}
+		return false, err
 	}
-	token = groups[1]
-	owner = groups[2]
-	repo = groups[3]
-	log.Debugf("found token %q, owner: %q, repo: %q", token, owner, repo)
-	return
+	return true, nil
 }
 
-// Head 回傳當前的 branch
-func Head(log *logrus.Logger, pwd string) string {
-	p := filepath.Join(pwd, ".git", "HEAD")
-	log.Debugf("loading git HEAD: %s", p)
-	b, err := ioutil.ReadFile(p)
-	if err != nil {
-		return ""
-	}
-	head := string(b)
-	lines := strings.Split(head, fmt.Sprintln())
-	if len(lines) < 1 {
-		return ""
+// notFound 將 404 的錯誤轉換成 scm.ErrNotFound
+func notFound(err error) error {
+	if githubErr, ok := err.(*github.ErrorResponse); ok && githubErr.Response.StatusCode == 404 {
+		return scm.ErrNotFound
 	}
-	return strings.ReplaceAll(lines[0], "ref: refs/heads/", "")
+	return err
 }
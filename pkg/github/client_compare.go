@@ -0,0 +1,87 @@
+package github
+
+import (
+	"github.com/google/go-github/v28/github"
+	"github.com/softleader/s2i/pkg/scm"
+	"regexp"
+)
+
+var (
+	// merge commit 及 squash merge 預設的 commit message 都會帶有 PR 編號
+	pullRequestRefs = []*regexp.Regexp{
+		regexp.MustCompile(`^Merge pull request #(\d+) `),
+		regexp.MustCompile(`^[^\n]*\(#(\d+)\)[ \t]*(?:\n|$)`),
+	}
+)
+
+// Changes 回傳 base 到 head 之間的 commits 及已 merge 的 pull requests, GitHub compare api 最多回傳 250 個 commits
+func (c *Client) Changes(base, head string) (commits []*scm.Commit, pulls []*scm.PullRequest, err error) {
+	c.log.Debugf("comparing %s...%s of %s/%s", base, head, c.owner, c.repo)
+	cmp, _, err := c.c.Repositories.CompareCommits(c.ctx, c.owner, c.repo, base, head)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, rc := range cmp.Commits {
+		author := rc.GetAuthor().GetLogin()
+		if author == "" {
+			author = rc.GetCommit().GetAuthor().GetName()
+		}
+		commits = append(commits, &scm.Commit{
+			SHA:     rc.GetSHA(),
+			Message: rc.GetCommit().GetMessage(),
+			Author:  author,
+		})
+	}
+	for _, n := range scm.PullRequestNumbers(commits, pullRequestRefs...) {
+		pr, err := c.pullRequest(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pr != nil {
+			pulls = append(pulls, pr)
+		}
+	}
+	return
+}
+
+// pullRequest 回傳已 merge 的 pull request 及其 commits, 不存在或尚未 merge 時回傳 nil
+func (c *Client) pullRequest(number int) (*scm.PullRequest, error) {
+	c.log.Debugf("fetching pull request #%d", number)
+	pr, _, err := c.c.PullRequests.Get(c.ctx, c.owner, c.repo, number)
+	if err != nil {
+		if notFound(err) == scm.ErrNotFound { // 可能只是 message 剛好符合格式, 或是參照到 issue
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !pr.GetMerged() {
+		return nil, nil
+	}
+	p := &scm.PullRequest{
+		Number:      number,
+		Title:       pr.GetTitle(),
+		Author:      pr.GetUser().GetLogin(),
+		MergeCommit: pr.GetMergeCommitSHA(),
+	}
+	for _, l := range pr.Labels {
+		p.Labels = append(p.Labels, l.GetName())
+	}
+	opt := &github.ListOptions{
+		Page:    1,
+		PerPage: 100,
+	}
+	for {
+		rcs, resp, err := c.c.PullRequests.ListCommits(c.ctx, c.owner, c.repo, number, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, rc := range rcs {
+			p.Commits = append(p.Commits, rc.GetSHA())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return p, nil
+}
@@ -1,64 +1,74 @@
 package github
 
 import (
-	"context"
 	"github.com/google/go-github/v28/github"
-	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
 )
 
 // CreateRelease 建立 github 的 release
-func CreateRelease(log *logrus.Logger, token, owner, repo, branch, tag string) (*Release, error) {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) CreateRelease(commitish, tag string, notes *scm.Notes) (*scm.Release, error) {
 	r := &github.RepositoryRelease{
 		TagName:         &tag,
-		TargetCommitish: &branch,
+		TargetCommitish: &commitish,
 	}
-	log.Debugf("creating release %s for %s/%s branch: %s", tag, owner, repo, branch)
-	release, _, err := client.Repositories.CreateRelease(ctx, owner, repo, r)
+	setNotes(r, notes)
+	c.log.Debugf("creating release %s for %s/%s commitish: %s", tag, c.owner, c.repo, commitish)
+	release, _, err := c.c.Repositories.CreateRelease(c.ctx, c.owner, c.repo, r)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Successfully created release: %s", release.GetHTMLURL())
+	c.log.Printf("Successfully created release: %s", release.GetHTMLURL())
 	return newRelease(release), nil
 }
 
 // CreatePrerelease 建立 github 的 pre-release
-func CreatePrerelease(log *logrus.Logger, token, owner, repo, branch, tag string, force bool) (*Release, error) {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) CreatePrerelease(commitish, tag string, notes *scm.Notes, force bool) (*scm.Release, error) {
 	pre := true
 	r := &github.RepositoryRelease{
 		TagName:         &tag,
-		TargetCommitish: &branch,
+		TargetCommitish: &commitish,
 		Prerelease:      &pre,
 	}
-	log.Debugf("creating pre-release %s for %s/%s branch: %s", tag, owner, repo, branch)
-	release, _, err := client.Repositories.CreateRelease(ctx, owner, repo, r)
+	setNotes(r, notes)
+	c.log.Debugf("creating pre-release %s for %s/%s commitish: %s", tag, c.owner, c.repo, commitish)
+	release, _, err := c.c.Repositories.CreateRelease(c.ctx, c.owner, c.repo, r)
 	if err != nil {
 		githubErr, ok := err.(*github.ErrorResponse)
 		if !ok {
 			return nil, err
 		}
 		if force && isTagNameAlreadyExists(githubErr.Errors) {
-			log.Debugf("tag name %s already exists, force to delete it..", tag)
-			if err := deleteReleaseAndTag(ctx, log, client, owner, repo, tag, false); err != nil {
+			c.log.Debugf("tag name %s already exists, force to delete it..", tag)
+			if err := c.DeleteReleaseAndTag(tag, false); err != nil {
 				return nil, err
 			}
 		}
-		log.Debugf("creating pre-release %s again for %s/%s branch: %s", tag, owner, repo, branch)
-		if release, _, err = client.Repositories.CreateRelease(ctx, owner, repo, r); err != nil {
+		c.log.Debugf("creating pre-release %s again for %s/%s commitish: %s", tag, c.owner, c.repo, commitish)
+		if release, _, err = c.c.Repositories.CreateRelease(c.ctx, c.owner, c.repo, r); err != nil {
 			return nil, err
 		}
 	}
 
-	log.Printf("Successfully created pre-release: %s", release.GetHTMLURL())
+	c.log.Printf("Successfully created pre-release: %s", release.GetHTMLURL())
+	return newRelease(release), nil
+}
+
+// CreateDraftRelease 建立 github 的 draft release, draft release 在 publish 前不會建立 tag
+func (c *Client) CreateDraftRelease(commitish, tag string, notes *scm.Notes, prerelease bool) (*scm.Release, error) {
+	draft := true
+	r := &github.RepositoryRelease{
+		TagName:         &tag,
+		TargetCommitish: &commitish,
+		Draft:           &draft,
+		Prerelease:      &prerelease,
+	}
+	setNotes(r, notes)
+	c.log.Debugf("creating draft release %s for %s/%s commitish: %s", tag, c.owner, c.repo, commitish)
+	release, _, err := c.c.Repositories.CreateRelease(c.ctx, c.owner, c.repo, r)
+	if err != nil {
+		return nil, err
+	}
+	c.log.Printf("Successfully created draft release: %s", release.GetHTMLURL())
 	return newRelease(release), nil
 }
 
@@ -70,3 +80,11 @@ func isTagNameAlreadyExists(errors []github.Error) bool {
 	}
 	return false
 }
+
+func setNotes(r *github.RepositoryRelease, notes *scm.Notes) {
+	if notes == nil {
+		return
+	}
+	r.Name = &notes.Name
+	r.Body = &notes.Body
+}
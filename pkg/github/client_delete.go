@@ -1,110 +1,79 @@
 package github
 
 import (
-	"context"
 	"fmt"
 	"github.com/google/go-github/v28/github"
-	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
 )
 
-// DeleteMatchesReleasesAndTags 刪除所有符合的 release 及其 tag
-func DeleteMatchesReleasesAndTags(log *logrus.Logger, token, owner, repo string, matcher TagMatcher, dryRun bool) error {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
+// DeleteReleaseAndTag 刪除 release 及其 refs/tag, release 及 tag 都不存在時回傳 scm.ErrNotFound
+func (c *Client) DeleteReleaseAndTag(tag string, dryRun bool) error {
+	releaseDeleted, err := c.deleteRelease(tag, dryRun)
 	if err != nil {
 		return err
 	}
-
-	opt := &github.ListOptions{
-		Page:    1,
-		PerPage: 100,
-	}
-	for {
-		log.Debugf("fetching page %v of tags", opt.Page)
-		tags, resp, err := client.Repositories.ListTags(ctx, owner, repo, opt)
-		if err != nil {
-			return err
-		}
-		for _, tag := range tags {
-			if name := tag.GetName(); len(name) > 0 {
-				if matcher.Matches(name) {
-					log.Infof("'%s' matches! start to delete it...", name)
-					if err := deleteReleaseAndTag(ctx, log, client, owner, repo, name, dryRun); err != nil {
-						return err
-					}
-					log.Infof("'%s' has been deleted from GitHub", name)
-				}
-			}
-		}
-		if resp.NextPage == 0 {
-			break
-		}
-		log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
-		opt.Page = resp.NextPage
-	}
-
-	return nil
-}
-
-// DeleteReleasesAndTags 刪除多筆 release 及其 refs/tag
-func DeleteReleasesAndTags(log *logrus.Logger, token, owner, repo string, tags []string, dryRun bool) error {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
+	tagDeleted, err := c.deleteTag(tag, dryRun)
 	if err != nil {
 		return err
 	}
-	for _, tag := range tags {
-		if err := deleteReleaseAndTag(ctx, log, client, owner, repo, tag, dryRun); err != nil {
-			return err
-		}
+	if !releaseDeleted && !tagDeleted {
+		return scm.ErrNotFound
 	}
 	return nil
 }
 
-// DeleteReleaseAndTag 刪除 release 及其 refs/tag
-func deleteReleaseAndTag(ctx context.Context, log *logrus.Logger, client *github.Client, owner, repo, tag string, dryRun bool) error {
-	if err := deleteRelease(ctx, log, client, owner, repo, tag, dryRun); err != nil {
-		return err
-	}
-	return deleteTag(ctx, log, client, owner, repo, tag, dryRun)
-}
-
-func deleteTag(ctx context.Context, log *logrus.Logger, client *github.Client, owner, repo, tag string, dryRun bool) error {
-	log.Debugf("deleting refs/tags %s", tag)
-	if !dryRun {
-		_, err := client.Git.DeleteRef(ctx, owner, repo, fmt.Sprintf("tags/%s", tag))
-		if err != nil {
-			githubErr, ok := err.(*github.ErrorResponse)
-			if !ok {
-				return err
+func (c *Client) deleteTag(tag string, dryRun bool) (bool, error) {
+	c.log.Debugf("deleting refs/tags %s", tag)
+	if dryRun { // 不實際刪除, 但仍要確認 tag 存在
+		if _, err := c.tagRef(tag); err != nil {
+			if err == scm.ErrNotFound {
+				return false, nil
 			}
-			if githubErr.Response.StatusCode == 422 { // 代表 ref 刪除動作失敗, 直接中斷不丟錯
-				return nil
-			}
-			return err
+			return false, err
 		}
+		return true, nil
 	}
-	return nil
+	err := c.call(func() (*github.Response, error) {
+		return c.c.Git.DeleteRef(c.ctx, c.owner, c.repo, fmt.Sprintf("tags/%s", tag))
+	})
+	if err != nil {
+		githubErr, ok := err.(*github.ErrorResponse)
+		if !ok {
+			return false, err
+		}
+		if githubErr.Response.StatusCode == 422 { // 代表 ref 不存在, 直接中斷不丟錯
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
-func deleteRelease(ctx context.Context, log *logrus.Logger, client *github.Client, owner, repo, tag string, dryRun bool) error {
-	log.Debugf("fetching release-id of tag '%s'", tag)
-	rr, _, err := client.Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+
+func (c *Client) deleteRelease(tag string, dryRun bool) (bool, error) {
+	c.log.Debugf("fetching release-id of tag '%s'", tag)
+	var rr *github.RepositoryRelease
+	err := c.call(func() (resp *github.Response, err error) {
+		rr, resp, err = c.c.Repositories.GetReleaseByTag(c.ctx, c.owner, c.repo, tag)
+		return
+	})
 	if err != nil {
 		githubErr, ok := err.(*github.ErrorResponse)
 		if !ok {
-			return err
+			return false, err
 		}
 		if githubErr.Response.StatusCode == 404 { // 代表 release 不存在, 直接中斷不丟錯
-			return nil
+			return false, nil
 		}
-		return err
+		return false, err
 	}
-	log.Debugf("deleting release %s by release-id %d", tag, rr.GetID())
+	c.log.Debugf("deleting release %s by release-id %d", tag, rr.GetID())
 	if !dryRun {
-		_, err = client.Repositories.DeleteRelease(ctx, owner, repo, rr.GetID())
+		err = c.call(func() (*github.Response, error) {
+			return c.c.Repositories.DeleteRelease(c.ctx, c.owner, c.repo, rr.GetID())
+		})
 		if err != nil {
-			return err
+			return false, err
 		}
 	}
-	return nil
+	return true, nil
 }
@@ -0,0 +1,20 @@
+package github
+
+import (
+	"github.com/softleader/s2i/pkg/scm"
+	"testing"
+)
+
+func TestDeleteTag_DryRun(t *testing.T) {
+	c, done := newTestClient(t)
+	defer done()
+	if deleted, err := c.deleteTag("1.2.3-0", true); err != nil || !deleted {
+		t.Errorf("existing tag should be reported as deleted, but got %v, %v", deleted, err)
+	}
+	if deleted, err := c.deleteTag("1.2.3", true); err != nil || deleted {
+		t.Errorf("missing tag should not be reported as deleted, but got %v, %v", deleted, err)
+	}
+	if err := c.DeleteReleaseAndTag("1.2.3", true); err != scm.ErrNotFound {
+		t.Errorf("should be scm.ErrNotFound, but got %v", err)
+	}
+}
@@ -1,67 +1,63 @@
 package github
 
 import (
-	"context"
 	"github.com/google/go-github/v28/github"
-	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
 )
 
-// ListReleaseByMatcher 依照指定 matcher 列出符合的 release 資訊
-func ListReleaseByMatcher(log *logrus.Logger, token, owner, repo string, matcher TagMatcher) error {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
-	if err != nil {
-		return err
-	}
-
+// ListReleases 列出所有的 release
+func (c *Client) ListReleases() (releases []*scm.Release, err error) {
 	opt := &github.ListOptions{
 		Page:    1,
 		PerPage: 100,
 	}
 	for {
-		log.Debugf("fetching page %v of tags", opt.Page)
-		releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, opt)
+		c.log.Debugf("fetching page %v of releases", opt.Page)
+		rrs, resp, err := c.c.Repositories.ListReleases(c.ctx, c.owner, c.repo, opt)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		for _, release := range releases {
-			if name := release.GetName(); len(name) > 0 {
-				if matcher.Matches(name) {
-					log.Infof("%s\t%s\t%s", name, release.GetPublishedAt(), release.GetAuthor().GetLogin())
-				}
-			}
+		for _, rr := range rrs {
+			releases = append(releases, newRelease(rr))
 		}
 		if resp.NextPage == 0 {
 			break
 		}
-		log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
+		c.log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
 		opt.Page = resp.NextPage
 	}
-
-	return nil
+	return releases, nil
 }
 
-// ListRelease 依照 release 名稱列出符合相關資訊
-func ListRelease(log *logrus.Logger, token, owner, repo string, tags []string) error {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
-	if err != nil {
-		return err
+// ListTags 列出所有的 tag 名稱
+func (c *Client) ListTags() (tags []string, err error) {
+	opt := &github.ListOptions{
+		Page:    1,
+		PerPage: 100,
 	}
-
-	for _, tag := range tags {
-		rr, _, err := client.Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+	for {
+		c.log.Debugf("fetching page %v of tags", opt.Page)
+		rts, resp, err := c.c.Repositories.ListTags(c.ctx, c.owner, c.repo, opt)
 		if err != nil {
-			githubErr, ok := err.(*github.ErrorResponse)
-			if !ok {
-				return err
-			}
-			if githubErr.Response.StatusCode == 404 { // 代表 release 不存在, 直接中斷不丟錯
-				return nil
-			}
-			return err
+			return nil, err
 		}
-		log.Infof("%s\t%s\t%s", rr.GetName(), rr.GetPublishedAt(), rr.GetAuthor().GetLogin())
+		for _, tag := range rts {
+			tags = append(tags, tag.GetName())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		c.log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
+		opt.Page = resp.NextPage
+	}
+	return tags, nil
+}
+
+// GetRelease 回傳 tag 的 release
+func (c *Client) GetRelease(tag string) (*scm.Release, error) {
+	rr, _, err := c.c.Repositories.GetReleaseByTag(c.ctx, c.owner, c.repo, tag)
+	if err != nil {
+		return nil, notFound(err)
 	}
-	return nil
+	return newRelease(rr), nil
 }
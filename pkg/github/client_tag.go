@@ -0,0 +1,135 @@
+package github
+
+import (
+	"github.com/google/go-github/v28/github"
+	"github.com/softleader/s2i/pkg/scm"
+)
+
+// ResolveTag 回傳 tag 指向的 commit SHA, annotated tag 會再往下找到其指向的 commit
+func (c *Client) ResolveTag(tag string) (string, error) {
+	c.log.Debugf("resolving refs/tags/%s of %s/%s", tag, c.owner, c.repo)
+	ref, err := c.tagRef(tag)
+	if err != nil {
+		return "", err
+	}
+	obj := ref.GetObject()
+	if obj.GetType() != "tag" {
+		return obj.GetSHA(), nil
+	}
+	t, _, err := c.c.Git.GetTag(c.ctx, c.owner, c.repo, obj.GetSHA())
+	if err != nil {
+		return "", notFound(err)
+	}
+	return t.GetObject().GetSHA(), nil
+}
+
+// TagMessage 回傳 annotated tag 的 message, lightweight tag 回傳空字串
+func (c *Client) TagMessage(tag string) (string, error) {
+	ref, err := c.tagRef(tag)
+	if err != nil {
+		return "", err
+	}
+	obj := ref.GetObject()
+	if obj.GetType() != "tag" {
+		return "", nil
+	}
+	t, _, err := c.c.Git.GetTag(c.ctx, c.owner, c.repo, obj.GetSHA())
+	if err != nil {
+		return "", notFound(err)
+	}
+	return t.GetMessage(), nil
+}
+
+// tagRef 回傳 refs/tags/<tag>, 不存在時回傳 scm.ErrNotFound;
+// GitHub 在沒有完全相符的 ref 時會回傳所有以此開頭的 refs (e.g. 查 1.2.3 會得到 1.2.3-0), 因此只接受完全相符的 ref
+func (c *Client) tagRef(tag string) (*github.Reference, error) {
+	refs, _, err := c.c.Git.GetRefs(c.ctx, c.owner, c.repo, "tags/"+tag)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	for _, ref := range refs {
+		if ref.GetRef() == "refs/tags/"+tag {
+			return ref, nil
+		}
+	}
+	return nil, scm.ErrNotFound
+}
+
+// CreateTag 在 commitish 上建立 tag, commitish 為 branch 時會先找出其最新的 commit
+func (c *Client) CreateTag(commitish, tag string, opts *scm.TagOptions) error {
+	sha, err := c.tagObject(commitish, tag, opts)
+	if err != nil {
+		return err
+	}
+	ref := "refs/tags/" + tag
+	c.log.Debugf("creating %s on %s for %s/%s", ref, sha, c.owner, c.repo)
+	return c.call(func() (resp *github.Response, err error) {
+		_, resp, err = c.c.Git.CreateRef(c.ctx, c.owner, c.repo, &github.Reference{
+			Ref:    &ref,
+			Object: &github.GitObject{SHA: &sha},
+		})
+		return
+	})
+}
+
+// MoveTag 將已存在的 tag 強制更新到 commitish 上
+func (c *Client) MoveTag(tag, commitish string, opts *scm.TagOptions) error {
+	ref := "refs/tags/" + tag
+	if _, err := c.tagRef(tag); err != nil {
+		return err
+	}
+	sha, err := c.tagObject(commitish, tag, opts)
+	if err != nil {
+		return err
+	}
+	c.log.Debugf("moving %s to %s for %s/%s", ref, sha, c.owner, c.repo)
+	return c.call(func() (resp *github.Response, err error) {
+		_, resp, err = c.c.Git.UpdateRef(c.ctx, c.owner, c.repo, &github.Reference{
+			Ref:    &ref,
+			Object: &github.GitObject{SHA: &sha},
+		}, true)
+		return
+	})
+}
+
+// tagObject 回傳 tag ref 要指向的 object SHA: lightweight tag 為 commit SHA, annotated tag 則會透過 git data api 建立 tag object
+func (c *Client) tagObject(commitish, tag string, opts *scm.TagOptions) (string, error) {
+	sha, _, err := c.c.Repositories.GetCommitSHA1(c.ctx, c.owner, c.repo, commitish, "")
+	if err != nil {
+		if githubErr, ok := err.(*github.ErrorResponse); ok {
+			if code := githubErr.Response.StatusCode; code == 404 || code == 422 {
+				return "", &scm.RefNotFoundError{Ref: commitish}
+			}
+		}
+		return "", err
+	}
+	if !opts.Annotated() {
+		return sha, nil
+	}
+	message, err := opts.SignedMessage(sha, tag)
+	if err != nil {
+		return "", err
+	}
+	t := &github.Tag{
+		Tag:     &tag,
+		Message: &message,
+		Object:  &github.GitObject{Type: github.String("commit"), SHA: &sha},
+	}
+	if tagger := opts.Tagger; tagger != nil {
+		t.Tagger = &github.CommitAuthor{
+			Name:  &tagger.Name,
+			Email: &tagger.Email,
+			Date:  &tagger.Date,
+		}
+	}
+	c.log.Debugf("creating annotated tag object %s on %s for %s/%s (signed: %v)", tag, sha, c.owner, c.repo, opts.Signed())
+	var created *github.Tag
+	err = c.call(func() (resp *github.Response, err error) {
+		created, resp, err = c.c.Git.CreateTag(c.ctx, c.owner, c.repo, t)
+		return
+	})
+	if err != nil {
+		return "", err
+	}
+	return created.GetSHA(), nil
+}
@@ -0,0 +1,103 @@
+package github
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// refs 是假的 GitHub 上的 tags, 查詢 git/refs/tags/<prefix> 時與 GitHub 相同: 完全相符回傳單一 ref, 否則回傳所有以此開頭的 refs
+var refs = map[string]string{
+	"1.0.0":   `{"ref":"refs/tags/1.0.0","object":{"type":"tag","sha":"t100"}}`,
+	"1.2.3-0": `{"ref":"refs/tags/1.2.3-0","object":{"type":"commit","sha":"c1230"}}`,
+	"1.2.3-1": `{"ref":"refs/tags/1.2.3-1","object":{"type":"commit","sha":"c1231"}}`,
+}
+
+// newTestClient 建立連到假的 GitHub Enterprise 的 client
+func newTestClient(t *testing.T) (*Client, func()) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/softleader/app/git/refs/tags/", func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimPrefix(r.URL.Path, "/api/v3/repos/softleader/app/git/refs/tags/")
+		if ref, found := refs[prefix]; found {
+			fmt.Fprint(w, ref)
+			return
+		}
+		var matched []string
+		for tag, ref := range refs {
+			if strings.HasPrefix(tag, prefix) {
+				matched = append(matched, ref)
+			}
+		}
+		if len(matched) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+			return
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(matched, ","))
+	})
+	mux.HandleFunc("/api/v3/repos/softleader/app/git/tags/t100", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"sha":"t100","message":"release 1.0.0","object":{"type":"commit","sha":"c100"}}`)
+	})
+	mux.HandleFunc("/api/v3/repos/softleader/app/commits/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"message":"No commit found for SHA: typo"}`)
+	})
+	server := httptest.NewServer(mux)
+	c, err := NewClient(logrus.New(), server.URL, "token", "softleader", "app")
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return c, server.Close
+}
+
+func TestResolveTag(t *testing.T) {
+	c, done := newTestClient(t)
+	defer done()
+	tests := []struct {
+		tag, sha string
+		err      error
+	}{
+		{"1.2.3-0", "c1230", nil},
+		{"1.0.0", "c100", nil},
+		{"1.2.3", "", scm.ErrNotFound}, // 只有 prefix 相符的 1.2.3-0 及 1.2.3-1
+		{"1.2", "", scm.ErrNotFound},
+		{"2.0.0", "", scm.ErrNotFound},
+	}
+	for _, tt := range tests {
+		sha, err := c.ResolveTag(tt.tag)
+		if sha != tt.sha || err != tt.err {
+			t.Errorf("%s: expected %q, %v, but got %q, %v", tt.tag, tt.sha, tt.err, sha, err)
+		}
+	}
+}
+
+func TestTagMessage(t *testing.T) {
+	c, done := newTestClient(t)
+	defer done()
+	if msg, err := c.TagMessage("1.0.0"); err != nil || msg != "release 1.0.0" {
+		t.Errorf("expected message of annotated tag 1.0.0, but got %q, %v", msg, err)
+	}
+	if msg, err := c.TagMessage("1.2.3-0"); err != nil || msg != "" {
+		t.Errorf("lightweight tag should have no message, but got %q, %v", msg, err)
+	}
+	if _, err := c.TagMessage("1.2.3"); err != scm.ErrNotFound {
+		t.Errorf("should be scm.ErrNotFound, but got %v", err)
+	}
+}
+
+func TestMoveTag_RefNotFound(t *testing.T) {
+	c, done := newTestClient(t)
+	defer done()
+	err := c.MoveTag("1.2.3-0", "typo", nil)
+	if e, ok := err.(*scm.RefNotFoundError); !ok || e.Ref != "typo" {
+		t.Errorf("should be ref not found of typo, but got %v", err)
+	}
+	if err := c.MoveTag("1.2.3", "typo", nil); err != scm.ErrNotFound {
+		t.Errorf("should be scm.ErrNotFound of the tag, but got %v", err)
+	}
+}
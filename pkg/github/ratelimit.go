@@ -0,0 +1,78 @@
+package github
+
+import (
+	"github.com/google/go-github/v28/github"
+	"sync"
+	"time"
+)
+
+const (
+	maxRetries = 3
+	// 超過此等待時間就不再重試, 直接回傳錯誤
+	maxBackoff = 5 * time.Minute
+	// secondary rate limit 沒有回傳 Retry-After 時的等待時間
+	defaultBackoff = time.Minute
+)
+
+// throttle 讓同一個 client 的所有 goroutine 共用等待時間, 避免在 rate limit 時還持續的打 api
+type throttle struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+func (t *throttle) wait() {
+	t.mu.Lock()
+	d := time.Until(t.until)
+	t.mu.Unlock()
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+func (t *throttle) delay(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := time.Now().Add(d); until.After(t.until) {
+		t.until = until
+	}
+}
+
+// call 執行 fn, 遇到 rate limit 時依照 Retry-After 或 X-RateLimit-Reset 等待後重試,
+// 當 X-RateLimit-Remaining 已經用完時, 之後的呼叫也會先等到 reset 後才執行
+func (c *Client) call(fn func() (*github.Response, error)) (err error) {
+	for i := 0; ; i++ {
+		c.throttle.wait()
+		var resp *github.Response
+		resp, err = fn()
+		d, retry := backoff(resp, err)
+		if d <= 0 {
+			return
+		}
+		if d > maxBackoff {
+			c.log.Debugf("rate limit will not be reset in %s, giving up", maxBackoff)
+			return
+		}
+		c.throttle.delay(d)
+		if !retry || i >= maxRetries {
+			return
+		}
+		c.log.Warnf("rate limit exceeded, retrying in %s", d.Round(time.Second))
+	}
+}
+
+// backoff 回傳需要等待的時間, 以及是否需要重試這次的呼叫
+func backoff(resp *github.Response, err error) (time.Duration, bool) {
+	switch e := err.(type) {
+	case *github.AbuseRateLimitError: // secondary rate limit, 會帶 Retry-After
+		if e.RetryAfter != nil {
+			return *e.RetryAfter, true
+		}
+		return defaultBackoff, true
+	case *github.RateLimitError:
+		return time.Until(e.Rate.Reset.Time), true
+	}
+	if resp != nil && resp.Rate.Limit > 0 && resp.Rate.Remaining == 0 {
+		return time.Until(resp.Rate.Reset.Time), false
+	}
+	return 0, false
+}
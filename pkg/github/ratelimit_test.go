@@ -0,0 +1,35 @@
+package github
+
+import (
+	"errors"
+	"github.com/google/go-github/v28/github"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	retryAfter := 30 * time.Second
+	reset := github.Timestamp{Time: time.Now().Add(time.Hour)}
+	tests := []struct {
+		name  string
+		resp  *github.Response
+		err   error
+		wait  bool
+		retry bool
+	}{
+		{"secondary rate limit", nil, &github.AbuseRateLimitError{RetryAfter: &retryAfter}, true, true},
+		{"primary rate limit", nil, &github.RateLimitError{Rate: github.Rate{Reset: reset}}, true, true},
+		{"remaining exhausted", &github.Response{Rate: github.Rate{Limit: 5000, Remaining: 0, Reset: reset}}, nil, true, false},
+		{"remaining available", &github.Response{Rate: github.Rate{Limit: 5000, Remaining: 10, Reset: reset}}, nil, false, false},
+		{"other error", nil, errors.New("boom"), false, false},
+	}
+	for _, tt := range tests {
+		d, retry := backoff(tt.resp, tt.err)
+		if (d > 0) != tt.wait || retry != tt.retry {
+			t.Errorf("%s: got wait %s, retry %v", tt.name, d, retry)
+		}
+	}
+	if d, _ := backoff(nil, &github.AbuseRateLimitError{RetryAfter: &retryAfter}); d != retryAfter {
+		t.Errorf("should wait Retry-After %s, but got %s", retryAfter, d)
+	}
+}
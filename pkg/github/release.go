@@ -1,29 +1,20 @@
 package github
 
-import "github.com/google/go-github/v28/github"
+import (
+	"github.com/google/go-github/v28/github"
+	"github.com/softleader/s2i/pkg/scm"
+)
 
-// Release wrap GitHub Repository Release
-type Release struct {
-	TagName         string
-	TargetCommitish string
-	Name            string
-	Draft           bool
-	Prerelease      bool
-
-	PublishedAt github.Timestamp
-	HTMLURL     string
-	Author      *github.User
-}
-
-func newRelease(rr *github.RepositoryRelease) *Release {
-	return &Release{
+func newRelease(rr *github.RepositoryRelease) *scm.Release {
+	return &scm.Release{
 		TagName:         rr.GetTagName(),
 		TargetCommitish: rr.GetTargetCommitish(),
 		Name:            rr.GetName(),
+		Body:            rr.GetBody(),
 		Draft:           rr.GetDraft(),
 		Prerelease:      rr.GetPrerelease(),
-		PublishedAt:     rr.GetPublishedAt(),
+		PublishedAt:     rr.GetPublishedAt().Time,
 		HTMLURL:         rr.GetHTMLURL(),
-		Author:          rr.GetAuthor(),
+		Author:          rr.GetAuthor().GetLogin(),
 	}
 }
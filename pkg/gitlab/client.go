@@ -0,0 +1,110 @@
+package gitlab
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/softleader/s2i/pkg/scm/restapi"
+	"gopkg.in/resty.v1"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	pathProject = "/projects/%s"
+	perPage     = "100"
+)
+
+var _ scm.SCM = &Client{}
+
+// Client 封裝了跟 GitLab 上某個 project 互動的 api, 實作了 scm.SCM
+type Client struct {
+	c       *resty.Client
+	log     *logrus.Logger
+	project string
+}
+
+// NewClient 建立跟 GitLab 互動的 client, baseURL 傳入 GitLab 的網址, e.g. https://gitlab.example.com
+func NewClient(log *logrus.Logger, baseURL, token, owner, repo string) *Client {
+	return &Client{
+		log:     log,
+		project: fmt.Sprintf(pathProject, url.PathEscape(owner+"/"+repo)),
+		c: restapi.NewClient(log, strings.TrimSuffix(baseURL, "/")+"/api/v4").
+			SetHeader("PRIVATE-TOKEN", token),
+	}
+}
+
+// newError 將非 2xx 的 response 轉換成 error, 404 時回傳 scm.ErrNotFound
+func newError(resp *resty.Response) error {
+	return restapi.NewError("gitlab", resp)
+}
+
+// get 依序取得每一頁的資料, 並交給 fn 處理
+func (c *Client) get(path string, fn func(body []byte) error) error {
+	page := "1"
+	for page != "" {
+		c.log.Debugf("fetching page %v of %s", page, path)
+		resp, err := c.c.R().
+			SetQueryParams(map[string]string{"page": page, "per_page": perPage}).
+			Get(path)
+		if err != nil {
+			return err
+		}
+		if !resp.IsSuccess() {
+			return newError(resp)
+		}
+		if err := fn(resp.Body()); err != nil {
+			return err
+		}
+		page = resp.Header().Get("X-Next-Page")
+	}
+	return nil
+}
+
+// CommitExists 判斷 commit 是否已經 push 到 GitLab 上
+func (c *Client) CommitExists(sha string) (bool, error) {
+	c.log.Debugf("checking if commit %s exists on %s", sha, c.project)
+	resp, err := c.c.R().Get(c.project + "/repository/commits/" + url.PathEscape(sha))
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode() == 404 {
+		return false, nil
+	}
+	if !resp.IsSuccess() {
+		return false, newError(resp)
+	}
+	return true, nil
+}
+
+type release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	ReleasedAt  time.Time `json:"released_at"`
+	Author      struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+	Links struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+// toRelease 轉換成 scm.Release, GitLab 沒有 pre-release 的概念, 因此以 tag 是否包含 semver 的 pre-release 版號判斷
+func (r *release) toRelease() *scm.Release {
+	return &scm.Release{
+		TagName:         r.TagName,
+		TargetCommitish: r.Commit.ID,
+		Name:            r.Name,
+		Body:            r.Description,
+		Prerelease:      scm.IsPrerelease(r.TagName),
+		PublishedAt:     r.ReleasedAt,
+		HTMLURL:         r.Links.Self,
+		Author:          r.Author.Username,
+	}
+}
@@ -0,0 +1,109 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/softleader/s2i/pkg/scm"
+	"regexp"
+)
+
+var (
+	// GitLab merge commit 預設的 commit message 會帶有 'See merge request group/project!123'
+	mergeRequestRef = regexp.MustCompile(`(?m)^See merge request \S*!(\d+)\s*$`)
+)
+
+// Changes 回傳 base 到 head 之間的 commits 及已 merge 的 merge requests
+func (c *Client) Changes(base, head string) (commits []*scm.Commit, pulls []*scm.PullRequest, err error) {
+	c.log.Debugf("comparing %s...%s of %s", base, head, c.project)
+	resp, err := c.c.R().
+		SetQueryParams(map[string]string{"from": base, "to": head}).
+		Get(c.project + "/repository/compare")
+	if err != nil {
+		return nil, nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, nil, newError(resp)
+	}
+	var cmp struct {
+		Commits []struct {
+			ID         string `json:"id"`
+			Message    string `json:"message"`
+			AuthorName string `json:"author_name"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal(resp.Body(), &cmp); err != nil {
+		return nil, nil, err
+	}
+	for _, cc := range cmp.Commits {
+		commits = append(commits, &scm.Commit{
+			SHA:     cc.ID,
+			Message: cc.Message,
+			Author:  cc.AuthorName,
+		})
+	}
+	for _, iid := range scm.PullRequestNumbers(commits, mergeRequestRef) {
+		mr, err := c.mergeRequest(iid)
+		if err != nil {
+			return nil, nil, err
+		}
+		if mr != nil {
+			pulls = append(pulls, mr)
+		}
+	}
+	return
+}
+
+// mergeRequest 回傳已 merge 的 merge request 及其 commits, 不存在或尚未 merge 時回傳 nil
+func (c *Client) mergeRequest(iid int) (*scm.PullRequest, error) {
+	c.log.Debugf("fetching merge request !%d", iid)
+	path := fmt.Sprintf("%s/merge_requests/%d", c.project, iid)
+	resp, err := c.c.R().Get(path)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		if err := newError(resp); err != scm.ErrNotFound {
+			return nil, err
+		}
+		return nil, nil
+	}
+	var mr struct {
+		Title           string   `json:"title"`
+		State           string   `json:"state"`
+		Labels          []string `json:"labels"`
+		MergeCommitSHA  string   `json:"merge_commit_sha"`
+		SquashCommitSHA string   `json:"squash_commit_sha"`
+		Author          struct {
+			Username string `json:"username"`
+		} `json:"author"`
+	}
+	if err := json.Unmarshal(resp.Body(), &mr); err != nil {
+		return nil, err
+	}
+	if mr.State != "merged" {
+		return nil, nil
+	}
+	p := &scm.PullRequest{
+		Number: iid,
+		Title:  mr.Title,
+		Labels: mr.Labels,
+		Author: mr.Author.Username,
+	}
+	// squash 時 MR 的 commits 不會出現在 compare 中, 取而代之的是 squash commit (merge commit 本身會因 message 被略過)
+	if p.MergeCommit = mr.SquashCommitSHA; p.MergeCommit == "" {
+		p.MergeCommit = mr.MergeCommitSHA
+	}
+	err = c.get(path+"/commits", func(body []byte) error {
+		var cs []struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(body, &cs); err != nil {
+			return err
+		}
+		for _, c := range cs {
+			p.Commits = append(p.Commits, c.ID)
+		}
+		return nil
+	})
+	return p, err
+}
@@ -0,0 +1,75 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/softleader/s2i/pkg/scm"
+	"github.com/softleader/s2i/pkg/scm/restapi"
+)
+
+// CreateRelease 建立 GitLab 的 release
+func (c *Client) CreateRelease(commitish, tag string, notes *scm.Notes) (*scm.Release, error) {
+	c.log.Debugf("creating release %s for %s commitish: %s", tag, c.project, commitish)
+	r, err := c.createRelease(commitish, tag, notes)
+	if err != nil {
+		return nil, err
+	}
+	c.log.Printf("Successfully created release: %s", r.HTMLURL)
+	return r, nil
+}
+
+// CreatePrerelease 建立 GitLab 的 pre-release, 在 GitLab 上與 release 並無差別
+func (c *Client) CreatePrerelease(commitish, tag string, notes *scm.Notes, force bool) (*scm.Release, error) {
+	c.log.Debugf("creating pre-release %s for %s commitish: %s", tag, c.project, commitish)
+	r, err := c.createRelease(commitish, tag, notes)
+	if err != nil {
+		gitlabErr, ok := err.(*restapi.Error)
+		if !ok {
+			return nil, err
+		}
+		if force && gitlabErr.StatusCode == 409 { // 代表 release 或 tag 已存在
+			c.log.Debugf("tag name %s already exists, force to delete it..", tag)
+			if err := c.DeleteReleaseAndTag(tag, false); err != nil {
+				return nil, err
+			}
+		}
+		c.log.Debugf("creating pre-release %s again for %s commitish: %s", tag, c.project, commitish)
+		if r, err = c.createRelease(commitish, tag, notes); err != nil {
+			return nil, err
+		}
+	}
+	c.log.Printf("Successfully created pre-release: %s", r.HTMLURL)
+	return r, nil
+}
+
+// CreateDraftRelease GitLab 沒有 draft release
+func (c *Client) CreateDraftRelease(commitish, tag string, notes *scm.Notes, prerelease bool) (*scm.Release, error) {
+	return nil, fmt.Errorf("draft release is not supported on GitLab")
+}
+
+func (c *Client) createRelease(commitish, tag string, notes *scm.Notes) (*scm.Release, error) {
+	body := map[string]string{
+		"tag_name": tag,
+		"name":     tag,
+		"ref":      commitish,
+	}
+	if notes != nil {
+		body["name"] = notes.Name
+		body["description"] = notes.Body
+	}
+	resp, err := c.c.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).
+		Post(c.project + "/releases")
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, newError(resp)
+	}
+	var r release
+	if err := json.Unmarshal(resp.Body(), &r); err != nil {
+		return nil, err
+	}
+	return r.toRelease(), nil
+}
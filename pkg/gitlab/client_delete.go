@@ -0,0 +1,40 @@
+package gitlab
+
+import (
+	"github.com/softleader/s2i/pkg/scm"
+	"net/url"
+)
+
+// DeleteReleaseAndTag 刪除 release 及其 tag, release 及 tag 都不存在時回傳 scm.ErrNotFound
+func (c *Client) DeleteReleaseAndTag(tag string, dryRun bool) error {
+	releaseDeleted, err := c.delete("release", "/releases/", tag, dryRun)
+	if err != nil {
+		return err
+	}
+	tagDeleted, err := c.delete("tag", "/repository/tags/", tag, dryRun)
+	if err != nil {
+		return err
+	}
+	if !releaseDeleted && !tagDeleted {
+		return scm.ErrNotFound
+	}
+	return nil
+}
+
+func (c *Client) delete(kind, path, tag string, dryRun bool) (bool, error) {
+	c.log.Debugf("deleting %s %s", kind, tag)
+	if dryRun {
+		return true, nil
+	}
+	resp, err := c.c.R().Delete(c.project + path + url.PathEscape(tag))
+	if err != nil {
+		return false, err
+	}
+	if !resp.IsSuccess() {
+		if err := newError(resp); err != scm.ErrNotFound { // 不存在就直接中斷不丟錯
+			return false, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
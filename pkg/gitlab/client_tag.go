@@ -0,0 +1,138 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/softleader/s2i/pkg/scm"
+	"net/url"
+)
+
+type gitTag struct {
+	Message string `json:"message"`
+	Commit  struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+// ResolveTag 回傳 tag 指向的 commit SHA
+func (c *Client) ResolveTag(tag string) (string, error) {
+	c.log.Debugf("resolving tag %s of %s", tag, c.project)
+	t, err := c.getTag(tag)
+	if err != nil {
+		return "", err
+	}
+	return t.Commit.ID, nil
+}
+
+// TagMessage 回傳 annotated tag 的 message, lightweight tag 回傳空字串
+func (c *Client) TagMessage(tag string) (string, error) {
+	t, err := c.getTag(tag)
+	if err != nil {
+		return "", err
+	}
+	return t.Message, nil
+}
+
+func (c *Client) getTag(tag string) (*gitTag, error) {
+	resp, err := c.c.R().Get(c.project + "/repository/tags/" + url.PathEscape(tag))
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, newError(resp)
+	}
+	t := &gitTag{}
+	if err := json.Unmarshal(resp.Body(), t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// CreateTag 在 commitish 上建立 tag, 有 message 時建立 annotated tag, GitLab 的 api 不支援簽署 tag
+func (c *Client) CreateTag(commitish, tag string, opts *scm.TagOptions) error {
+	if opts.Signed() {
+		return fmt.Errorf("signed tag is not supported on GitLab, please sign and push the tag with git instead")
+	}
+	body := map[string]string{
+		"tag_name": tag,
+		"ref":      commitish,
+	}
+	if opts.Annotated() {
+		body["message"] = opts.Message
+	}
+	c.log.Debugf("creating tag %s on %s for %s", tag, commitish, c.project)
+	resp, err := c.c.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).
+		Post(c.project + "/repository/tags")
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return newError(resp)
+	}
+	return nil
+}
+
+// MoveTag 將已存在的 tag 移到 commitish 上, GitLab 沒有更新 tag 的 api, 因此會先刪除再重新建立;
+// 為了避免連同 release 一起被刪除, tag 已有 release 時會回傳錯誤, 重新建立失敗時會將 tag 還原到原本的 commit
+func (c *Client) MoveTag(tag, commitish string, opts *scm.TagOptions) error {
+	if opts.Signed() {
+		return fmt.Errorf("signed tag is not supported on GitLab, please sign and push the tag with git instead")
+	}
+	old, err := c.getTag(tag)
+	if err != nil {
+		return err
+	}
+	if _, err := c.GetRelease(tag); err == nil {
+		return fmt.Errorf("tag %s has a release on GitLab, moving it would delete the release", tag)
+	} else if err != scm.ErrNotFound {
+		return err
+	}
+	sha, err := c.resolveCommit(commitish)
+	if err != nil {
+		return err
+	}
+	c.log.Debugf("moving tag %s to %s for %s", tag, sha, c.project)
+	if _, err := c.delete("tag", "/repository/tags/", tag, false); err != nil {
+		return err
+	}
+	if err := c.CreateTag(sha, tag, opts); err != nil {
+		return c.restoreTag(tag, old, err)
+	}
+	return nil
+}
+
+// restoreTag 在移動 tag 失敗後, 以原本的 message 將 tag 重新建立在原本的 commit 上
+func (c *Client) restoreTag(tag string, old *gitTag, cause error) error {
+	c.log.Debugf("restoring tag %s on %s for %s", tag, old.Commit.ID, c.project)
+	var opts *scm.TagOptions
+	if old.Message != "" {
+		opts = &scm.TagOptions{Message: old.Message}
+	}
+	if err := c.CreateTag(old.Commit.ID, tag, opts); err != nil {
+		return fmt.Errorf("failed to move tag %s: %s, and failed to restore it on %s: %s", tag, cause, old.Commit.ID, err)
+	}
+	return fmt.Errorf("failed to move tag %s, it has been restored on %s: %s", tag, old.Commit.ID, cause)
+}
+
+// resolveCommit 回傳 commitish (branch, tag 或 commit SHA) 指向的 commit SHA, 不存在時回傳 *scm.RefNotFoundError
+func (c *Client) resolveCommit(commitish string) (string, error) {
+	resp, err := c.c.R().Get(c.project + "/repository/commits/" + url.PathEscape(commitish))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode() == 404 {
+		return "", &scm.RefNotFoundError{Ref: commitish}
+	}
+	if !resp.IsSuccess() {
+		return "", newError(resp)
+	}
+	var commit struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(resp.Body(), &commit); err != nil {
+		return "", err
+	}
+	return commit.ID, nil
+}
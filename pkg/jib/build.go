@@ -17,8 +17,8 @@ var (
 )
 
 // DockerBuild to Docker daemon by jib
-func DockerBuild(log *logrus.Logger, image *docker.SoftleaderHubImage, updateSnapshots bool) error {
-	args := []string{"compile", "jib:dockerBuild", "-Dbuild.image=" + image.Name, "-Dbuild.tag=" + image.Tag}
+func DockerBuild(log *logrus.Logger, image *docker.Image, updateSnapshots bool) error {
+	args := []string{"compile", "jib:dockerBuild", "-Djib.to.image=" + image.String(), "-Dbuild.image=" + image.Name, "-Dbuild.tag=" + image.Tag}
 	if updateSnapshots {
 		args = append(args, "-U")
 	}
@@ -46,8 +46,8 @@ func (a *Auth) IsValid() bool {
 }
 
 // Build image by jib
-func Build(log *logrus.Logger, image *docker.SoftleaderHubImage, auth *Auth, updateSnapshots bool) error {
-	args := []string{"compile", "jib:build", "-Djib.to.auth.username=" + auth.Username, "-Djib.to.auth.password=" + auth.Password, "-Dbuild.image=" + image.Name, "-Dbuild.tag=" + image.Tag}
+func Build(log *logrus.Logger, image *docker.Image, auth *Auth, updateSnapshots bool) error {
+	args := []string{"compile", "jib:build", "-Djib.to.auth.username=" + auth.Username, "-Djib.to.auth.password=" + auth.Password, "-Djib.to.image=" + image.String(), "-Dbuild.image=" + image.Name, "-Dbuild.tag=" + image.Tag}
 	if updateSnapshots {
 		args = append(args, "-U")
 	}
@@ -0,0 +1,211 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/mitchellh/go-homedir"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	dir = ".s2i/journal"
+)
+
+// Entry 代表一筆被刪除的 tag 及其 release, 足以重新建立 tag 及 release
+type Entry struct {
+	DeletedAt  time.Time `json:"deleted_at"`
+	Tag        string    `json:"tag"`
+	SHA        string    `json:"sha"`
+	Message    string    `json:"message,omitempty"` // annotated tag 的 message, lightweight tag 為空
+	Release    bool      `json:"release"`           // 刪除前是否有 release, 沒有時只會重建 tag
+	Name       string    `json:"name,omitempty"`
+	Body       string    `json:"body,omitempty"`
+	Prerelease bool      `json:"prerelease,omitempty"`
+	Draft      bool      `json:"draft,omitempty"`
+}
+
+// Path 回傳 owner/repo 的 journal 路徑, e.g. $HOME/.s2i/journal/softleader/s2i.jsonl
+func Path(owner, repo string) (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, dir, owner, repo+".jsonl"), nil
+}
+
+// Journal 以每行一筆 json 的格式記錄被刪除的 tag 及 release, 可同時被多個 goroutine 寫入
+type Journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New 建立寫入 path 的 Journal
+func New(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Path 回傳 journal 的路徑
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Append 新增一筆記錄到 journal 最後
+func (j *Journal) Append(e *Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Read 讀取 journal 中所有的記錄, 同一個 tag 只會保留最後一次刪除的記錄, journal 不存在時回傳空的結果
+func Read(path string) (entries []*Entry, err error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	index := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024) // release body 可能很長
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		e := &Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil {
+			return nil, err
+		}
+		if i, found := index[e.Tag]; found {
+			entries[i] = e
+			continue
+		}
+		index[e.Tag] = len(entries)
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Snapshot 取得 tag 及其 release 目前的資訊, tag 及 release 都不存在時回傳 scm.ErrNotFound
+func Snapshot(s scm.SCM, tag string) (*Entry, error) {
+	e := &Entry{Tag: tag}
+	sha, err := s.ResolveTag(tag)
+	if err != nil && err != scm.ErrNotFound {
+		return nil, err
+	}
+	e.SHA = sha
+	if sha != "" {
+		if e.Message, err = s.TagMessage(tag); err != nil {
+			return nil, err
+		}
+	}
+	rr, err := getRelease(s, tag)
+	if err == scm.ErrNotFound {
+		if e.SHA == "" {
+			return nil, scm.ErrNotFound
+		}
+		return e, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Release = true
+	e.Name = rr.Name
+	e.Body = rr.Body
+	e.Prerelease = rr.Prerelease
+	e.Draft = rr.Draft
+	if e.SHA == "" { // 只有 release 沒有 tag, 如 draft release
+		e.SHA = rr.TargetCommitish
+	}
+	return e, nil
+}
+
+// getRelease 取得 tag 的 release, 找不到時再從 ListReleases 中找 draft release (如 GitHub 依 tag 查詢時不會回傳 draft)
+func getRelease(s scm.SCM, tag string) (*scm.Release, error) {
+	rr, err := s.GetRelease(tag)
+	if err != scm.ErrNotFound {
+		return rr, err
+	}
+	releases, err := s.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range releases {
+		if r.Draft && r.TagName == tag {
+			return r, nil
+		}
+	}
+	return nil, scm.ErrNotFound
+}
+
+// Wrap 回傳在刪除 tag 及 release 前, 會先將其資訊記錄到 j 的 SCM, dry run 時不會記錄;
+// 記錄會在刪除前寫入, 因此刪除失敗時 journal 中也可能有仍存在的 tag, restore 時會略過
+func Wrap(s scm.SCM, j *Journal) scm.SCM {
+	return &journaled{SCM: s, j: j}
+}
+
+type journaled struct {
+	scm.SCM
+	j *Journal
+}
+
+func (s *journaled) DeleteReleaseAndTag(tag string, dryRun bool) error {
+	if dryRun {
+		return s.SCM.DeleteReleaseAndTag(tag, dryRun)
+	}
+	e, err := Snapshot(s.SCM, tag)
+	if err != nil {
+		return err
+	}
+	e.DeletedAt = time.Now()
+	if err := s.j.Append(e); err != nil {
+		return err
+	}
+	return s.SCM.DeleteReleaseAndTag(tag, dryRun)
+}
+
+// Restore 依照 e 重新建立 tag, 刪除前有 release 的話也會一併建立 release;
+// annotated tag 會以原本的 message 重建, draft release 也會重建為 draft
+func Restore(log *logrus.Logger, s scm.SCM, e *Entry) error {
+	var opts *scm.TagOptions
+	if e.Message != "" {
+		opts = &scm.TagOptions{Message: e.Message}
+	}
+	if !e.Release || opts != nil { // release 會建立 lightweight tag, 因此 annotated tag 要先自行建立
+		log.Debugf("restoring tag %s on %s", e.Tag, e.SHA)
+		if err := s.CreateTag(e.SHA, e.Tag, opts); err != nil || !e.Release {
+			return err
+		}
+	}
+	log.Debugf("restoring release %s on %s", e.Tag, e.SHA)
+	notes := &scm.Notes{Name: e.Name, Body: e.Body}
+	if e.Draft {
+		_, err := s.CreateDraftRelease(e.SHA, e.Tag, notes, e.Prerelease)
+		return err
+	}
+	if e.Prerelease {
+		_, err := s.CreatePrerelease(e.SHA, e.Tag, notes, false)
+		return err
+	}
+	_, err := s.CreateRelease(e.SHA, e.Tag, notes)
+	return err
+}
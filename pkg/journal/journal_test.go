@@ -0,0 +1,169 @@
+package journal
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeSCM 只實作 journal 會用到的 method
+type fakeSCM struct {
+	scm.SCM
+	tags     map[string]string
+	messages map[string]string
+	releases map[string]*scm.Release
+	failing  map[string]bool // 刪除時會失敗的 tag
+}
+
+func (f *fakeSCM) ResolveTag(tag string) (string, error) {
+	if sha, found := f.tags[tag]; found {
+		return sha, nil
+	}
+	return "", scm.ErrNotFound
+}
+
+func (f *fakeSCM) TagMessage(tag string) (string, error) {
+	if _, found := f.tags[tag]; !found {
+		return "", scm.ErrNotFound
+	}
+	return f.messages[tag], nil
+}
+
+func (f *fakeSCM) CreateTag(commitish, tag string, opts *scm.TagOptions) error {
+	f.tags[tag] = commitish
+	if opts.Annotated() {
+		f.messages[tag] = opts.Message
+	}
+	return nil
+}
+
+func (f *fakeSCM) CreateRelease(commitish, tag string, notes *scm.Notes) (*scm.Release, error) {
+	if _, found := f.tags[tag]; !found {
+		f.tags[tag] = commitish
+	}
+	r := &scm.Release{TagName: tag, TargetCommitish: commitish, Name: notes.Name, Body: notes.Body}
+	f.releases[tag] = r
+	return r, nil
+}
+
+func (f *fakeSCM) CreatePrerelease(commitish, tag string, notes *scm.Notes, force bool) (*scm.Release, error) {
+	r, err := f.CreateRelease(commitish, tag, notes)
+	r.Prerelease = true
+	return r, err
+}
+
+func (f *fakeSCM) CreateDraftRelease(commitish, tag string, notes *scm.Notes, prerelease bool) (*scm.Release, error) {
+	r := &scm.Release{TagName: tag, TargetCommitish: commitish, Name: notes.Name, Body: notes.Body, Draft: true, Prerelease: prerelease}
+	f.releases[tag] = r
+	return r, nil
+}
+
+// GetRelease 與 GitHub 相同, 不會回傳 draft release
+func (f *fakeSCM) GetRelease(tag string) (*scm.Release, error) {
+	if r, found := f.releases[tag]; found && !r.Draft {
+		return r, nil
+	}
+	return nil, scm.ErrNotFound
+}
+
+func (f *fakeSCM) ListReleases() (releases []*scm.Release, err error) {
+	for _, r := range f.releases {
+		releases = append(releases, r)
+	}
+	return
+}
+
+func (f *fakeSCM) DeleteReleaseAndTag(tag string, dryRun bool) error {
+	if f.failing[tag] {
+		return errors.New("boom")
+	}
+	if !dryRun {
+		delete(f.tags, tag)
+		delete(f.releases, tag)
+	}
+	return nil
+}
+
+func TestWrap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "softleader", "s2i.jsonl")
+
+	s := &fakeSCM{
+		tags:     map[string]string{"1.0.0": "aaa", "1.1.0-0": "bbb", "1.2.0": "ccc"},
+		messages: map[string]string{"1.0.0": "release 1.0.0"},
+		releases: map[string]*scm.Release{
+			"1.1.0-0": {TagName: "1.1.0-0", Name: "1.1.0-0", Body: "## What's Changed", Prerelease: true},
+			"1.3.0":   {TagName: "1.3.0", TargetCommitish: "ddd", Draft: true},
+		},
+		failing: map[string]bool{"1.2.0": true},
+	}
+	js := Wrap(s, New(path))
+	if err := js.DeleteReleaseAndTag("1.1.0-0", true); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"1.0.0", "1.1.0-0", "2.0.0", "1.3.0"} {
+		if err := js.DeleteReleaseAndTag(tag, false); err != nil && err != scm.ErrNotFound {
+			t.Fatal(err)
+		}
+	}
+	if err := js.DeleteReleaseAndTag("1.2.0", false); err == nil {
+		t.Error("should return the error of deleting 1.2.0")
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("should record 4 entries, but got %d", len(entries))
+	}
+	if e := entries[0]; e.Tag != "1.0.0" || e.SHA != "aaa" || e.Message != "release 1.0.0" || e.Release {
+		t.Errorf("unexpected entry of 1.0.0: %+v", e)
+	}
+	if e := entries[1]; e.Tag != "1.1.0-0" || e.SHA != "bbb" || !e.Release || !e.Prerelease || e.Body != "## What's Changed" {
+		t.Errorf("unexpected entry of 1.1.0-0: %+v", e)
+	}
+	if e := entries[2]; e.Tag != "1.3.0" || e.SHA != "ddd" || !e.Release || !e.Draft {
+		t.Errorf("unexpected entry of 1.3.0: %+v", e)
+	}
+	if e := entries[3]; e.Tag != "1.2.0" || e.SHA != "ccc" {
+		t.Errorf("entry should be recorded before deleting 1.2.0: %+v", e)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	s := &fakeSCM{
+		tags:     make(map[string]string),
+		messages: make(map[string]string),
+		releases: make(map[string]*scm.Release),
+	}
+	for _, e := range []*Entry{
+		{Tag: "1.0.0", SHA: "aaa", Message: "release 1.0.0"},
+		{Tag: "1.1.0", SHA: "bbb", Message: "release 1.1.0", Release: true, Name: "1.1.0"},
+		{Tag: "1.2.0", SHA: "ccc", Release: true, Name: "1.2.0", Draft: true},
+	} {
+		if err := Restore(logrus.StandardLogger(), s, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.messages["1.0.0"] != "release 1.0.0" || s.releases["1.0.0"] != nil {
+		t.Errorf("1.0.0 should be restored as an annotated tag without release")
+	}
+	if s.messages["1.1.0"] != "release 1.1.0" || s.releases["1.1.0"] == nil {
+		t.Errorf("1.1.0 should be restored as an annotated tag with release")
+	}
+	if r := s.releases["1.2.0"]; r == nil || !r.Draft {
+		t.Errorf("1.2.0 should be restored as a draft release, but got %+v", r)
+	}
+	if _, found := s.tags["1.2.0"]; found {
+		t.Errorf("draft release 1.2.0 should not create a tag")
+	}
+}
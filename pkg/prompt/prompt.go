@@ -2,10 +2,11 @@ package prompt
 
 import (
 	"errors"
+	"fmt"
 	"github.com/manifoldco/promptui"
 	"github.com/sirupsen/logrus"
 	"github.com/softleader/s2i/pkg/deployer"
-	"github.com/softleader/s2i/pkg/github"
+	"github.com/softleader/s2i/pkg/scm"
 	"gopkg.in/yaml.v2"
 	"strconv"
 	"strings"
@@ -122,49 +123,73 @@ func AskYesNo(question, defaultValue string, ref *bool) (err error) {
 	return
 }
 
-// AskServiceID 問 docker swarm serviceID 問題
-func AskServiceID(log *logrus.Logger, agent, agentVersion, deployerURL, app, defaultValue string, size int, ref *string) (err error) {
-	question := "Service id to update image (leave blank if you don't need to update)"
+// AskServiceID 問 docker swarm serviceID 問題, 可以選擇多個 service
+func AskServiceID(d *deployer.Client, app string, defaultValue []string, size int, ref *[]string) (err error) {
+	question := "Service ids to update image (use space to separate each id if more than one, leave blank if you don't need to update)"
 
-	if defaultValue != "" {
-		return Ask(question, defaultValue, ref)
+	if len(defaultValue) > 0 {
+		return askServiceIDs(question, defaultValue, ref)
 	}
 
-	services, err := deployer.FilterServiceByApp(log, agent, agentVersion, deployerURL, app)
+	services, err := d.FilterServiceByApp(app)
+	if err != nil { // 連線發生問題時不中斷, 改為讓使用者自行輸入
+		logrus.Debugf("failed to filter services of %s from deployer: %s", app, err)
+	}
 	if len(services) == 0 || err != nil { // 在 deployer 上找不到任何已部署的服務, 或連線發生問題
-		return Ask(question, defaultValue, ref)
+		return askServiceIDs(question, defaultValue, ref)
 	}
 
-	services = append([]deployer.DockerService{{
-		Name: "I don't need to update",
-	}}, services...)
-	prompt := promptui.Select{
-		Label: "Select docker service to update",
-		Items: services,
-		Templates: &promptui.SelectTemplates{
-			Active:   promptui.IconSelect + " {{ .Name }}\t{{ .Ports }}",
-			Inactive: "  {{ .Name }}\t{{ .Ports }}",
-			Selected: promptui.IconGood + " {{ .Name }}\t{{ .Ports }}",
-		},
-		Searcher: func(input string, index int) bool {
-			channel := services[index]
-			name := strings.Replace(strings.ToLower(channel.Name), " ", "", -1)
-			input = strings.Replace(strings.ToLower(input), " ", "", -1)
-			return strings.Contains(name, input)
-		},
-		Size: size,
-	}
-	i, _, err := prompt.Run()
-	if err != nil {
+	// promptui 不支援多選, 因此每次選擇一個 service 後將其移出選項, 直到選擇第一個選項為止
+	var selected []string
+	for len(services) > 0 {
+		done := "I don't need to update"
+		if len(selected) > 0 {
+			done = fmt.Sprintf("Done (%d selected: %s)", len(selected), strings.Join(selected, ", "))
+		}
+		items := append([]deployer.DockerService{{Name: done}}, services...)
+		prompt := promptui.Select{
+			Label: "Select docker services to update",
+			Items: items,
+			Templates: &promptui.SelectTemplates{
+				Active:   promptui.IconSelect + " {{ .Name }}\t{{ .Ports }}",
+				Inactive: "  {{ .Name }}\t{{ .Ports }}",
+				Selected: promptui.IconGood + " {{ .Name }}\t{{ .Ports }}",
+			},
+			Searcher: func(input string, index int) bool {
+				channel := items[index]
+				name := strings.Replace(strings.ToLower(channel.Name), " ", "", -1)
+				input = strings.Replace(strings.ToLower(input), " ", "", -1)
+				return strings.Contains(name, input)
+			},
+			Size: size,
+		}
+		i, _, err := prompt.Run()
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			break
+		}
+		selected = append(selected, items[i].ID)
+		services = append(services[:i-1], services[i:]...)
+	}
+	*ref = selected
+	return nil
+}
+
+// askServiceIDs 讓使用者自行輸入以空白分隔的 service ids
+func askServiceIDs(question string, defaultValue []string, ref *[]string) error {
+	var ans string
+	if err := Ask(question, strings.Join(defaultValue, " "), &ans); err != nil {
 		return err
 	}
-	*ref = services[i].ID
+	*ref = strings.Fields(ans)
 	return nil
 }
 
 // AskTagMatcherStrategy 問 tag matcher  問題
-func AskTagMatcherStrategy(question string, strategy *github.TagMatcherStrategy) (err error) {
-	matchers := []string{"exact match", "regex", "semver"}
+func AskTagMatcherStrategy(question string, strategy *scm.TagMatcherStrategy) (err error) {
+	matchers := []string{"exact match", "regex", "semver", "glob"}
 	prompt := promptui.Select{
 		Label: question,
 		Items: matchers,
@@ -183,6 +208,8 @@ func AskTagMatcherStrategy(question string, strategy *github.TagMatcherStrategy)
 		strategy.Regex = true
 	} else if i == 2 {
 		strategy.SemVer = true
+	} else if i == 3 {
+		strategy.Glob = true
 	}
 	return nil
 }
@@ -0,0 +1,107 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/resty.v1"
+	"regexp"
+	"strings"
+)
+
+var (
+	challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// pullScope 回傳讀取 repository 所需的 scope
+func pullScope(name string) string {
+	return fmt.Sprintf("repository:%s:pull", name)
+}
+
+// pushScope 回傳寫入 repository 所需的 scope
+func pushScope(name string) string {
+	return fmt.Sprintf("repository:%s:pull,push", name)
+}
+
+// deleteScope 回傳刪除 repository 中 manifest 所需的 scope
+func deleteScope(name string) string {
+	return fmt.Sprintf("repository:%s:delete", name)
+}
+
+// authorize 依照之前取得的授權設定 request
+func (c *Client) authorize(r *resty.Request, scope string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if token, ok := c.tokens[scope]; ok {
+		r.SetAuthToken(token)
+		return
+	}
+	if c.basic {
+		r.SetBasicAuth(c.username, c.password)
+	}
+}
+
+// login 依照 WWW-Authenticate challenge 取得授權, 支援 Basic 及 Bearer (token server)
+func (c *Client) login(challenge, scope string) error {
+	scheme := strings.ToLower(strings.SplitN(challenge, " ", 2)[0])
+	switch scheme {
+	case "basic":
+		if c.username == "" {
+			return fmt.Errorf("registry %s requires username and password", c.host)
+		}
+		c.mu.Lock()
+		c.basic = true
+		c.mu.Unlock()
+		return nil
+	case "bearer":
+		token, err := c.fetchToken(challenge, scope)
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.tokens[scope] = token
+		c.mu.Unlock()
+		return nil
+	}
+	return fmt.Errorf("unsupported auth challenge of registry %s: %q", c.host, challenge)
+}
+
+// fetchToken 向 challenge 中的 realm 取得 bearer token
+func (c *Client) fetchToken(challenge, scope string) (string, error) {
+	params := make(map[string]string)
+	for _, m := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("missing realm in auth challenge of registry %s: %q", c.host, challenge)
+	}
+	c.log.Debugf("fetching token of %s from %s", scope, realm)
+	r := resty.New().SetDisableWarn(true).R().SetQueryParam("scope", scope)
+	if service := params["service"]; service != "" {
+		r.SetQueryParam("service", service)
+	}
+	if c.username != "" {
+		r.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := r.Get(realm)
+	if err != nil {
+		return "", err
+	}
+	if !resp.IsSuccess() {
+		return "", fmt.Errorf("failed to fetch token of %s from %s: %s", scope, realm, resp.Status())
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("no token responded from %s", realm)
+}
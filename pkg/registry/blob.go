@@ -0,0 +1,22 @@
+package registry
+
+import (
+	"fmt"
+	"gopkg.in/resty.v1"
+)
+
+// BlobExists 判斷 name 中是否存在 digest 的 blob (layer 或 image config)
+func (c *Client) BlobExists(name, digest string) (bool, error) {
+	c.log.Debugf("checking if blob %s exists in %s on %s", digest, name, c.host)
+	resp, err := c.do(resty.MethodHead, fmt.Sprintf("/%s/blobs/%s", name, digest), pullScope(name), nil)
+	if err != nil {
+		return false, err
+	}
+	if !resp.IsSuccess() {
+		if err := newError(resp); err != ErrNotFound {
+			return false, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,120 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/resty.v1"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxRetries       = 3
+	retryWaitTime    = 5 * time.Second
+	retryMaxWaitTime = time.Minute
+)
+
+var (
+	// ErrNotFound 代表要查詢的 manifest 或 blob 不存在
+	ErrNotFound = errors.New("not found")
+)
+
+// Client 封裝了跟 docker registry 互動的 Registry HTTP API V2
+type Client struct {
+	c        *resty.Client
+	log      *logrus.Logger
+	host     string
+	username string
+	password string
+
+	mu      sync.Mutex
+	tokens  map[string]string            // 依照 scope 記錄取得的 bearer token
+	basic   bool                         // registry 要求 basic auth
+	digests map[string]map[string]string // 依照 name 記錄每個 tag 的 manifest digest, 刪除時用來檢查是否有共用
+}
+
+// NewClient 建立跟 docker registry 互動的 client, host 不包含 scheme 時使用 https, e.g. hub.softleader.com.tw
+func NewClient(log *logrus.Logger, host, username, password string) *Client {
+	baseURL := host
+	if !strings.Contains(host, "://") {
+		baseURL = "https://" + host
+	}
+	return &Client{
+		log:      log,
+		host:     host,
+		username: username,
+		password: password,
+		tokens:   make(map[string]string),
+		digests:  make(map[string]map[string]string),
+		c: resty.New().
+			SetHostURL(strings.TrimSuffix(baseURL, "/") + "/v2").
+			SetDisableWarn(true).
+			SetDebug(log.IsLevelEnabled(logrus.DebugLevel)).
+			SetRetryCount(maxRetries).
+			SetRetryWaitTime(retryWaitTime).
+			SetRetryMaxWaitTime(retryMaxWaitTime).
+			AddRetryCondition(tooManyRequests),
+	}
+}
+
+// Error 代表 registry 回傳了非 2xx 的 status code
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("registry responded %d: %s", e.StatusCode, e.Message)
+}
+
+// newError 將非 2xx 的 response 轉換成 error, 404 時回傳 ErrNotFound
+func newError(resp *resty.Response) error {
+	if resp.StatusCode() == http.StatusNotFound {
+		return ErrNotFound
+	}
+	var body struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	e := &Error{StatusCode: resp.StatusCode(), Message: resp.Status()}
+	if err := json.Unmarshal(resp.Body(), &body); err == nil && len(body.Errors) > 0 {
+		var messages []string
+		for _, be := range body.Errors {
+			messages = append(messages, fmt.Sprintf("%s: %s", be.Code, be.Message))
+		}
+		e.Message = strings.Join(messages, ", ")
+	}
+	return e
+}
+
+// tooManyRequests 在觸發 rate limit 時重試, 會以 exponential backoff 等待
+func tooManyRequests(resp *resty.Response) (bool, error) {
+	return resp != nil && resp.StatusCode() == http.StatusTooManyRequests, nil
+}
+
+// do 以 scope 的權限執行 request, 收到 401 時會依照 challenge 取得授權後再重試一次
+func (c *Client) do(method, path, scope string, fn func(r *resty.Request)) (*resty.Response, error) {
+	for retried := false; ; retried = true {
+		r := c.c.R()
+		if fn != nil {
+			fn(r)
+		}
+		c.authorize(r, scope)
+		resp, err := r.Execute(method, path)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode() != http.StatusUnauthorized || retried {
+			return resp, nil
+		}
+		if err := c.login(resp.Header().Get("WWW-Authenticate"), scope); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry 模擬需要 bearer token 的 registry, manifests 以 name:reference 為 key
+type fakeRegistry struct {
+	mu        sync.Mutex
+	manifests map[string]string
+	blobs     map[string]bool
+	scopes    []string
+}
+
+func newFakeRegistry() (*fakeRegistry, *httptest.Server) {
+	f := &fakeRegistry{manifests: make(map[string]string), blobs: make(map[string]bool)}
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		f.scopes = append(f.scopes, r.URL.Query().Get("scope"))
+		f.mu.Unlock()
+		fmt.Fprintf(w, `{"token":%q}`, r.URL.Query().Get("scope"))
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		name, kind, ref := splitPath(r.URL.Path)
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch kind {
+		case "tags":
+			f.listTags(w, r, name)
+		case "blobs":
+			if !f.blobs[ref] {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case "manifests":
+			f.manifest(w, r, name, ref)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server = httptest.NewServer(mux)
+	return f, server
+}
+
+// listTags 以 n 及 last 分頁列出 tags
+func (f *fakeRegistry) listTags(w http.ResponseWriter, r *http.Request, name string) {
+	var tags []string
+	for key := range f.manifests {
+		if strings.HasPrefix(key, name+":") {
+			tags = append(tags, strings.TrimPrefix(key, name+":"))
+		}
+	}
+	sort.Strings(tags)
+	n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+	last := r.URL.Query().Get("last")
+	var page []string
+	for _, tag := range tags {
+		if tag > last && len(page) < n {
+			page = append(page, tag)
+		}
+	}
+	if len(page) > 0 && page[len(page)-1] != tags[len(tags)-1] {
+		w.Header().Set("Link", fmt.Sprintf(`</v2/%s/tags/list?last=%s&n=%d>; rel="next"`, name, page[len(page)-1], n))
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"name": name, "tags": page})
+}
+
+func (f *fakeRegistry) manifest(w http.ResponseWriter, r *http.Request, name, ref string) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		m, ok := f.manifests[name+":"+ref]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`)
+			return
+		}
+		w.Header().Set("Content-Type", MediaTypeManifest)
+		w.Header().Set(headerDigest, digest(m))
+		fmt.Fprint(w, m)
+	case http.MethodPut:
+		if !strings.HasSuffix(r.Header.Get("Authorization"), "pull,push") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("Content-Type") != MediaTypeManifest {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		f.manifests[name+":"+ref] = string(b)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		if !strings.HasPrefix(ref, "sha256:") { // 與 docker distribution 相同, 只能以 digest 刪除
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for key, m := range f.manifests {
+			if strings.HasPrefix(key, name+":") && digest(m) == ref {
+				delete(f.manifests, key)
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func digest(m string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(m)))
+}
+
+// splitPath 將 /v2/<name>/<kind>/<reference> 拆開, name 可以包含 '/'
+func splitPath(p string) (name, kind, ref string) {
+	parts := strings.Split(strings.TrimPrefix(p, "/v2/"), "/")
+	if len(parts) < 3 {
+		return
+	}
+	n := len(parts)
+	return strings.Join(parts[:n-2], "/"), parts[n-2], parts[n-1]
+}
+
+func TestRetag(t *testing.T) {
+	f, server := newFakeRegistry()
+	defer server.Close()
+	f.manifests["team/app:1.2.3-0"] = `{"layers":[]}`
+
+	c := NewClient(logrus.StandardLogger(), server.URL, "user", "pass")
+	m, err := c.Retag("team/app", "1.2.3-0", "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.MediaType != MediaTypeManifest {
+		t.Errorf("expected media type %s, but got %s", MediaTypeManifest, m.MediaType)
+	}
+	if actual := f.manifests["team/app:1.2.3"]; actual != `{"layers":[]}` {
+		t.Errorf("expected the manifest to be copied, but got %q", actual)
+	}
+	if expected := []string{"repository:team/app:pull", "repository:team/app:pull,push"}; fmt.Sprint(f.scopes) != fmt.Sprint(expected) {
+		t.Errorf("expected tokens of %v, but got %v", expected, f.scopes)
+	}
+
+	if _, err := c.GetManifest("team/app", "9.9.9"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, but got %v", err)
+	}
+	if _, err := NewClient(logrus.StandardLogger(), server.URL, "user", "wrong").GetManifest("team/app", "1.2.3"); err == nil {
+		t.Error("should fail with wrong password")
+	}
+}
+
+func TestTags(t *testing.T) {
+	f, server := newFakeRegistry()
+	defer server.Close()
+	var expected []string
+	for i := 0; i < 150; i++ {
+		tag := fmt.Sprintf("1.0.%03d", i)
+		f.manifests["app:"+tag] = tag
+		expected = append(expected, tag)
+	}
+	c := NewClient(logrus.StandardLogger(), server.URL, "user", "pass")
+	tags, err := c.Tags("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(tags) != fmt.Sprint(expected) {
+		t.Errorf("expected %d tags across pages, but got %d", len(expected), len(tags))
+	}
+	if tags, err := c.Tags("unknown"); err != nil || len(tags) != 0 {
+		t.Errorf("expected no tags of unknown repository, but got %v, %v", tags, err)
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	f, server := newFakeRegistry()
+	defer server.Close()
+	f.manifests["app:1.2.3-0"] = "tested"
+	f.manifests["app:1.2.3"] = "tested" // promote 後的正式版共用相同的 manifest
+	f.manifests["app:1.2.4-0"] = "another"
+	f.blobs["sha256:layer"] = true
+
+	c := NewClient(logrus.StandardLogger(), server.URL, "user", "pass")
+	if err := c.DeleteTag("app", "1.2.3-0"); err == nil || !strings.Contains(err.Error(), "1.2.3") {
+		t.Errorf("should refuse to delete the manifest shared with 1.2.3, but got %v", err)
+	}
+	if err := c.DeleteTag("app", "1.2.4-0"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := c.Exists("app", "1.2.4-0"); err != nil || exists {
+		t.Errorf("1.2.4-0 should be deleted, but got %v, %v", exists, err)
+	}
+	if exists, err := c.Exists("app", "1.2.3"); err != nil || !exists {
+		t.Errorf("1.2.3 should still exist, but got %v, %v", exists, err)
+	}
+	if err := c.DeleteTag("app", "1.2.4-0"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, but got %v", err)
+	}
+	if exists, err := c.BlobExists("app", "sha256:layer"); err != nil || !exists {
+		t.Errorf("blob should exist, but got %v, %v", exists, err)
+	}
+	if exists, err := c.BlobExists("app", "sha256:unknown"); err != nil || exists {
+		t.Errorf("blob should not exist, but got %v, %v", exists, err)
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := `{"auths":{"https://hub.softleader.com.tw/v2/":{"auth":"dXNlcjpwYTpzcw=="},"other.io":{"username":"u","password":"p"}}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("DOCKER_CONFIG", dir)
+	defer os.Unsetenv("DOCKER_CONFIG")
+
+	if u, p := Credentials(logrus.StandardLogger(), "hub.softleader.com.tw"); u != "user" || p != "pa:ss" {
+		t.Errorf("expected user/pa:ss, but got %s/%s", u, p)
+	}
+	if u, p := Credentials(logrus.StandardLogger(), "other.io"); u != "u" || p != "p" {
+		t.Errorf("expected u/p, but got %s/%s", u, p)
+	}
+	if u, _ := Credentials(logrus.StandardLogger(), "unknown.io"); u != "" {
+		t.Errorf("expected no credentials, but got %s", u)
+	}
+}
@@ -0,0 +1,114 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/mitchellh/go-homedir"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// dockerConfig 是 docker login 後寫入的 $HOME/.docker/config.json
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// Credentials 從 docker login 的設定中找出 host 的帳密, 支援 config.json 中的 auths, credHelpers 及 credsStore, 找不到時回傳空字串
+func Credentials(log *logrus.Logger, host string) (username, password string) {
+	path, err := dockerConfigPath()
+	if err != nil {
+		log.Debugln(err)
+		return
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Debugln(err)
+		return
+	}
+	var config dockerConfig
+	if err := json.Unmarshal(b, &config); err != nil {
+		log.Debugf("failed to parse %s: %s", path, err)
+		return
+	}
+	if helper := config.CredHelpers[host]; helper != "" {
+		return credentialsFromHelper(log, helper, host)
+	}
+	for server, auth := range config.Auths {
+		if serverHost(server) != host {
+			continue
+		}
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				log.Debugf("failed to decode auth of %s in %s: %s", server, path, err)
+				continue
+			}
+			if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+				return parts[0], parts[1]
+			}
+		}
+		if auth.Username != "" {
+			return auth.Username, auth.Password
+		}
+	}
+	if config.CredsStore != "" {
+		return credentialsFromHelper(log, config.CredsStore, host)
+	}
+	return
+}
+
+// dockerConfigPath 回傳 docker 的 config.json 位置, 與 docker 相同優先使用 $DOCKER_CONFIG
+func dockerConfigPath() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json"), nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// serverHost 將 config.json 中 auths 的 key 轉換成 host, e.g. https://hub.softleader.com.tw/v2/ 為 hub.softleader.com.tw
+func serverHost(server string) string {
+	if i := strings.Index(server, "://"); i >= 0 {
+		server = server[i+3:]
+	}
+	return strings.SplitN(server, "/", 2)[0]
+}
+
+// credentialsFromHelper 透過 docker-credential-<helper> 取得 host 的帳密
+func credentialsFromHelper(log *logrus.Logger, helper, host string) (username, password string) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	if log.IsLevelEnabled(logrus.DebugLevel) {
+		log.Out.Write([]byte(fmt.Sprintln(strings.Join(cmd.Args, " "))))
+	}
+	cmd.Stdin = strings.NewReader(host)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		log.Debugf("failed to get credentials of %s from %s: %s", host, helper, err)
+		return
+	}
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		log.Debugf("failed to parse credentials of %s from %s: %s", host, helper, err)
+		return
+	}
+	return creds.Username, creds.Secret
+}
@@ -0,0 +1,191 @@
+package registry
+
+import (
+	"fmt"
+	"gopkg.in/resty.v1"
+	"strings"
+)
+
+const (
+	// MediaTypeManifest 是 docker image manifest v2 schema 2
+	MediaTypeManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	// MediaTypeManifestList 是 multi-arch 的 docker manifest list
+	MediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	// MediaTypeOCIManifest 是 OCI image manifest
+	MediaTypeOCIManifest = "application/vnd.oci.image.manifest.v1+json"
+	// MediaTypeOCIIndex 是 multi-arch 的 OCI image index
+	MediaTypeOCIIndex = "application/vnd.oci.image.index.v1+json"
+
+	headerDigest = "Docker-Content-Digest"
+)
+
+var (
+	acceptedManifests = strings.Join([]string{MediaTypeManifest, MediaTypeManifestList, MediaTypeOCIManifest, MediaTypeOCIIndex}, ", ")
+)
+
+// Manifest 代表 registry 中 image 的 manifest, Body 保留原始內容, 重新 put 時 digest 才會一致
+type Manifest struct {
+	MediaType string
+	Digest    string
+	Body      []byte
+}
+
+// GetManifest 取得 name 中 reference (tag 或 digest) 的 manifest, 不存在時回傳 ErrNotFound
+func (c *Client) GetManifest(name, reference string) (*Manifest, error) {
+	c.log.Debugf("fetching manifest %s:%s from %s", name, reference, c.host)
+	resp, err := c.do(resty.MethodGet, manifestPath(name, reference), pullScope(name), func(r *resty.Request) {
+		r.SetHeader("Accept", acceptedManifests)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, newError(resp)
+	}
+	return &Manifest{
+		MediaType: strings.TrimSpace(strings.SplitN(resp.Header().Get("Content-Type"), ";", 2)[0]),
+		Digest:    resp.Header().Get(headerDigest),
+		Body:      resp.Body(),
+	}, nil
+}
+
+// ManifestDigest 回傳 name 中 reference 的 manifest digest, 只查詢 header 而不下載內容, 不存在時回傳 ErrNotFound
+func (c *Client) ManifestDigest(name, reference string) (string, error) {
+	resp, err := c.do(resty.MethodHead, manifestPath(name, reference), pullScope(name), func(r *resty.Request) {
+		r.SetHeader("Accept", acceptedManifests)
+	})
+	if err != nil {
+		return "", err
+	}
+	if !resp.IsSuccess() {
+		return "", newError(resp)
+	}
+	return resp.Header().Get(headerDigest), nil
+}
+
+// Exists 判斷 name 中是否已存在 tag
+func (c *Client) Exists(name, tag string) (bool, error) {
+	c.log.Debugf("checking if image %s:%s exists on %s", name, tag, c.host)
+	if _, err := c.ManifestDigest(name, tag); err != nil {
+		if err == ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// PutManifest 將 manifest 以 tag 寫入 name 中
+func (c *Client) PutManifest(name, tag string, m *Manifest) error {
+	c.log.Debugf("putting manifest %s:%s to %s", name, tag, c.host)
+	resp, err := c.do(resty.MethodPut, manifestPath(name, tag), pushScope(name), func(r *resty.Request) {
+		r.SetHeader("Content-Type", m.MediaType).SetBody(m.Body)
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return newError(resp)
+	}
+	return nil
+}
+
+// Retag 將 name 中 src tag 的 manifest 複製成 dst tag, 不需要 pull 任何 layer, 兩個 tag 會指向完全相同的 image
+func (c *Client) Retag(name, src, dst string) (*Manifest, error) {
+	m, err := c.GetManifest(name, src)
+	if err != nil {
+		if err == ErrNotFound {
+			return nil, fmt.Errorf("image %s/%s:%s not found", c.host, name, src)
+		}
+		return nil, err
+	}
+	if err := c.PutManifest(name, dst, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DeleteTag 刪除 name 中 tag 的 manifest, tag 不存在時回傳 ErrNotFound
+// registry 只能以 digest 刪除 manifest, 會連同指向相同 digest 的其他 tag (如 promote 後的正式版) 一起刪除, 因此有其他 tag 共用時會回傳錯誤
+func (c *Client) DeleteTag(name, tag string) error {
+	digest, err := c.ManifestDigest(name, tag)
+	if err != nil {
+		return err
+	}
+	shared, err := c.tagsOf(name, digest)
+	if err != nil {
+		return err
+	}
+	for _, t := range shared {
+		if t != tag {
+			return fmt.Errorf("image %s/%s:%s shares the same manifest %s with tag %s, deleting it would delete both", c.host, name, tag, digest, t)
+		}
+	}
+	c.log.Debugf("deleting manifest %s of %s:%s from %s", digest, name, tag, c.host)
+	resp, err := c.do(resty.MethodDelete, manifestPath(name, digest), deleteScope(name), nil)
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return newError(resp)
+	}
+	c.forget(name, tag)
+	return nil
+}
+
+// tagsOf 回傳 name 中指向 digest 的所有 tag, 每個 name 的 tag 及 digest 只會查詢一次
+func (c *Client) tagsOf(name, digest string) (tags []string, err error) {
+	digests, err := c.digestsOf(name)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for tag, d := range digests {
+		if d == digest {
+			tags = append(tags, tag)
+		}
+	}
+	return
+}
+
+func (c *Client) digestsOf(name string) (map[string]string, error) {
+	c.mu.Lock()
+	digests, ok := c.digests[name]
+	c.mu.Unlock()
+	if ok {
+		return digests, nil
+	}
+	tags, err := c.Tags(name)
+	if err != nil {
+		return nil, err
+	}
+	digests = make(map[string]string)
+	for _, tag := range tags {
+		d, err := c.ManifestDigest(name, tag)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		digests[tag] = d
+	}
+	c.mu.Lock()
+	c.digests[name] = digests
+	c.mu.Unlock()
+	return digests, nil
+}
+
+// forget 將已刪除的 tag 從快取中移除
+func (c *Client) forget(name, tag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if digests, ok := c.digests[name]; ok {
+		delete(digests, tag)
+	}
+}
+
+func manifestPath(name, reference string) string {
+	return fmt.Sprintf("/%s/manifests/%s", name, reference)
+}
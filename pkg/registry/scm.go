@@ -0,0 +1,31 @@
+package registry
+
+import (
+	"github.com/softleader/s2i/pkg/scm"
+)
+
+// Wrap 將 s 包裝成刪除 release 及 tag 後, 也會一併刪除 registry 上同名 tag 的 image 的 scm.SCM
+func Wrap(s scm.SCM, c *Client, name string) scm.SCM {
+	return &imageSCM{SCM: s, c: c, name: name}
+}
+
+type imageSCM struct {
+	scm.SCM
+	c    *Client
+	name string
+}
+
+// DeleteReleaseAndTag 刪除 release 及 tag 後, 再刪除同名 tag 的 image, image 不存在時不回傳錯誤
+func (s *imageSCM) DeleteReleaseAndTag(tag string, dryRun bool) error {
+	if err := s.SCM.DeleteReleaseAndTag(tag, dryRun); err != nil {
+		return err
+	}
+	if dryRun {
+		s.c.log.Debugf("image %s/%s:%s would be deleted", s.c.host, s.name, tag)
+		return nil
+	}
+	if err := s.c.DeleteTag(s.name, tag); err != nil && err != ErrNotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/resty.v1"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	perPage = "100"
+)
+
+var (
+	nextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+// Tags 列出 name 中所有的 tag
+func (c *Client) Tags(name string) (tags []string, err error) {
+	c.log.Debugf("listing tags of %s from %s", name, c.host)
+	path := fmt.Sprintf("/%s/tags/list?n=%s", name, perPage)
+	for path != "" {
+		resp, err := c.do(resty.MethodGet, path, pullScope(name), nil)
+		if err != nil {
+			return nil, err
+		}
+		if !resp.IsSuccess() {
+			if err := newError(resp); err != ErrNotFound { // repository 不存在代表沒有任何 tag
+				return nil, err
+			}
+			return nil, nil
+		}
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		if err := json.Unmarshal(resp.Body(), &body); err != nil {
+			return nil, err
+		}
+		tags = append(tags, body.Tags...)
+		path = nextPage(resp.Header().Get("Link"))
+	}
+	sort.Strings(tags)
+	return
+}
+
+// nextPage 從 Link header 找出下一頁的 path, 回傳的 path 不包含 /v2 prefix, 沒有下一頁時回傳空字串
+func nextPage(link string) string {
+	m := nextLink.FindStringSubmatch(link)
+	if len(m) < 2 {
+		return ""
+	}
+	u, err := url.Parse(m[1])
+	if err != nil {
+		return ""
+	}
+	path := strings.TrimPrefix(u.Path, "/v2")
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
@@ -0,0 +1,41 @@
+package scm
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	conventional = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+	breaking     = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:`)
+)
+
+// ConventionalCommit 代表依照 https://www.conventionalcommits.org 解析後的 commit message
+type ConventionalCommit struct {
+	Type        string // 不符合 conventional commits 格式時為空
+	Scope       string
+	Description string
+	Breaking    bool
+}
+
+// ParseConventionalCommit 解析 commit message, 不符合格式時 Description 為 message 的第一行
+func ParseConventionalCommit(message string) *ConventionalCommit {
+	message = strings.TrimSpace(message)
+	subject := message
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		subject = strings.TrimSpace(message[:i])
+	}
+	c := &ConventionalCommit{
+		Description: subject,
+		Breaking:    breaking.MatchString(message),
+	}
+	groups := conventional.FindStringSubmatch(subject)
+	if len(groups) == 0 {
+		return c
+	}
+	c.Type = strings.ToLower(groups[1])
+	c.Scope = groups[2]
+	c.Breaking = c.Breaking || groups[3] == "!"
+	c.Description = groups[4]
+	return c
+}
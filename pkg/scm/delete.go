@@ -0,0 +1,111 @@
+package scm
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"sync"
+)
+
+// DeleteSummary 代表批次刪除 tag 的結果
+type DeleteSummary struct {
+	Deleted []string
+	Skipped []string // release 及 tag 都不存在
+	Failed  map[string]error
+}
+
+// Err 有任何 tag 刪除失敗時回傳錯誤
+func (s *DeleteSummary) Err() error {
+	if len(s.Failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to delete %d tag(s)", len(s.Failed))
+}
+
+// Print 印出刪除的結果
+func (s *DeleteSummary) Print(log *logrus.Logger, dryRun bool) {
+	verb := "deleted"
+	if dryRun {
+		verb = "would be deleted"
+	}
+	for _, tag := range s.Skipped {
+		log.Infof("'%s' skipped: release and tag not found", tag)
+	}
+	var failed []string
+	for tag := range s.Failed {
+		failed = append(failed, tag)
+	}
+	sort.Strings(failed)
+	for _, tag := range failed {
+		log.Errorf("'%s' failed: %s", tag, s.Failed[tag])
+	}
+	log.Infof("%d %s, %d skipped, %d failed", len(s.Deleted), verb, len(s.Skipped), len(s.Failed))
+}
+
+// FindMatchedTags 找出所有符合 matcher 的 tag 名稱
+func FindMatchedTags(log *logrus.Logger, s SCM, matcher TagMatcher) ([]string, error) {
+	tags, err := s.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	var matched []string
+	for _, name := range tags {
+		if len(name) > 0 && matcher.Matches(name) {
+			log.Debugf("'%s' matches!", name)
+			matched = append(matched, name)
+		}
+	}
+	return matched, nil
+}
+
+// DeleteMatchesReleasesAndTags 以 parallel 個 worker 刪除所有符合的 release 及其 tag
+func DeleteMatchesReleasesAndTags(log *logrus.Logger, s SCM, matcher TagMatcher, parallel int, dryRun bool) (*DeleteSummary, error) {
+	matched, err := FindMatchedTags(log, s, matcher)
+	if err != nil {
+		return nil, err
+	}
+	return DeleteReleasesAndTags(log, s, matched, parallel, dryRun), nil
+}
+
+// DeleteReleasesAndTags 以 parallel 個 worker 刪除多筆 release 及其 tag, 任何一筆失敗都不會中斷其他的刪除
+func DeleteReleasesAndTags(log *logrus.Logger, s SCM, tags []string, parallel int, dryRun bool) *DeleteSummary {
+	if parallel < 1 {
+		parallel = 1
+	}
+	summary := &DeleteSummary{Failed: make(map[string]error)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tag := range queue {
+				err := s.DeleteReleaseAndTag(tag, dryRun)
+				mu.Lock()
+				switch err {
+				case nil:
+					if dryRun {
+						log.Infof("'%s' would be deleted", tag)
+					} else {
+						log.Infof("'%s' has been deleted", tag)
+					}
+					summary.Deleted = append(summary.Deleted, tag)
+				case ErrNotFound:
+					summary.Skipped = append(summary.Skipped, tag)
+				default:
+					summary.Failed[tag] = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, tag := range tags {
+		queue <- tag
+	}
+	close(queue)
+	wg.Wait()
+	sort.Strings(summary.Deleted)
+	sort.Strings(summary.Skipped)
+	return summary
+}
@@ -0,0 +1,117 @@
+package scm
+
+import (
+	"github.com/blang/semver"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+)
+
+const (
+	// StatusTagOnly 代表只有 tag 沒有 release
+	StatusTagOnly = "tag only"
+	// StatusReleaseOnly 代表只有 release 沒有 tag, e.g. draft release
+	StatusReleaseOnly = "release only"
+)
+
+// FindTagsByMatcher 合併所有的 tags 及 releases, 以 tag 名稱找出符合 matcher 的項目, 並依照版本由新到舊排序
+// 只有 tag 沒有 release 的項目 Status 為 StatusTagOnly, 只有 release 沒有 tag 的項目 Status 為 StatusReleaseOnly
+func FindTagsByMatcher(log *logrus.Logger, s SCM, matcher TagMatcher) ([]*Release, error) {
+	log.Debugf("fetching tags")
+	tags, err := s.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("fetching releases")
+	releases, err := s.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+	var merged []*Release
+	for _, r := range mergeTagsAndReleases(tags, releases) {
+		if matcher.Matches(r.TagName) {
+			merged = append(merged, r)
+		}
+	}
+	SortBySemVer(merged)
+	return merged, nil
+}
+
+// FindTags 依照 tag 名稱找出 release, 沒有 release 的 tag 其 Status 為 StatusTagOnly, tag 及 release 都不存在時會被略過
+func FindTags(log *logrus.Logger, s SCM, tags []string) ([]*Release, error) {
+	var found []*Release
+	var existing map[string]bool
+	for _, tag := range tags {
+		rr, err := s.GetRelease(tag)
+		if err == nil {
+			found = append(found, rr)
+			continue
+		}
+		if err != ErrNotFound {
+			return nil, err
+		}
+		if existing == nil { // 只有在需要時才 scan 所有的 tag
+			log.Debugf("release of %s not found, fetching tags", tag)
+			names, err := s.ListTags()
+			if err != nil {
+				return nil, err
+			}
+			existing = make(map[string]bool)
+			for _, name := range names {
+				existing[name] = true
+			}
+		}
+		if !existing[tag] {
+			log.Debugf("tag %s not found, skipping", tag)
+			continue
+		}
+		found = append(found, &Release{TagName: tag, Status: StatusTagOnly})
+	}
+	SortBySemVer(found)
+	return found, nil
+}
+
+func mergeTagsAndReleases(tags []string, releases []*Release) (merged []*Release) {
+	byTag := make(map[string]*Release)
+	for _, r := range releases {
+		byTag[r.TagName] = r
+	}
+	existing := make(map[string]bool)
+	for _, tag := range tags {
+		existing[tag] = true
+		if r, found := byTag[tag]; found {
+			merged = append(merged, r)
+		} else {
+			merged = append(merged, &Release{TagName: tag, Status: StatusTagOnly})
+		}
+	}
+	for _, r := range releases {
+		if !existing[r.TagName] {
+			r.Status = StatusReleaseOnly
+			merged = append(merged, r)
+		}
+	}
+	return
+}
+
+// SortBySemVer 依照 tag 的 semantic version 由新到舊排序, 不符合 semver 的 tag 排在最後並依名稱排序
+func SortBySemVer(releases []*Release) {
+	versions := make(map[*Release]*semver.Version)
+	for _, r := range releases {
+		if v, err := semver.Parse(strings.TrimPrefix(r.TagName, "v")); err == nil {
+			versions[r] = &v
+		}
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		vi, vj := versions[releases[i]], versions[releases[j]]
+		switch {
+		case vi != nil && vj != nil:
+			return vi.GT(*vj)
+		case vi != nil:
+			return true
+		case vj != nil:
+			return false
+		}
+		return releases[i].TagName > releases[j].TagName
+	})
+}
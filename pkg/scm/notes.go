@@ -0,0 +1,208 @@
+package scm
+
+import (
+	"fmt"
+	"github.com/blang/semver"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	sectionBreaking = "Breaking Changes"
+	sectionFeatures = "Features"
+	sectionFixes    = "Bug Fixes"
+	sectionPerf     = "Performance Improvements"
+	sectionRefactor = "Code Refactoring"
+	sectionDocs     = "Documentation"
+	sectionOthers   = "Others"
+)
+
+var (
+	sections = []string{sectionBreaking, sectionFeatures, sectionFixes, sectionPerf, sectionRefactor, sectionDocs, sectionOthers}
+	// conventional commit type 對應的段落, 沒列出的都歸到 Others
+	typeSections = map[string]string{
+		"feat":     sectionFeatures,
+		"feature":  sectionFeatures,
+		"fix":      sectionFixes,
+		"perf":     sectionPerf,
+		"refactor": sectionRefactor,
+		"docs":     sectionDocs,
+	}
+	// PR label 對應的段落, label 的優先權高於 PR title 的 conventional commit type
+	labelSections = map[string]string{
+		"breaking":        sectionBreaking,
+		"breaking-change": sectionBreaking,
+		"feature":         sectionFeatures,
+		"enhancement":     sectionFeatures,
+		"bug":             sectionFixes,
+		"bugfix":          sectionFixes,
+		"fix":             sectionFixes,
+		"performance":     sectionPerf,
+		"refactor":        sectionRefactor,
+		"refactoring":     sectionRefactor,
+		"documentation":   sectionDocs,
+		"docs":            sectionDocs,
+	}
+	mergeCommit = regexp.MustCompile(`^Merge (branch|remote-tracking branch|pull request) `)
+)
+
+// Commit 代表一個 commit
+type Commit struct {
+	SHA     string
+	Message string
+	Author  string
+}
+
+// PullRequest 代表一個已 merge 的 pull request (GitLab 稱為 merge request)
+type PullRequest struct {
+	Number  int
+	Title   string
+	Labels  []string
+	Author  string
+	Commits []string // 此 PR 包含的 commit SHA
+	// MergeCommit 是 PR merge 後產生的 commit SHA, squash merge 時為 squash 後的 commit
+	MergeCommit string
+}
+
+// Notes 代表 release notes
+type Notes struct {
+	Name string
+	Body string
+}
+
+// PullRequestNumbers 依照 patterns 從 commit message 中找出所有參照到的 PR 編號, pattern 的第一個 group 必須是編號
+func PullRequestNumbers(commits []*Commit, patterns ...*regexp.Regexp) (numbers []int) {
+	found := make(map[int]bool)
+	for _, c := range commits {
+		for _, p := range patterns {
+			for _, groups := range p.FindAllStringSubmatch(c.Message, -1) {
+				if n, err := strconv.Atoi(groups[1]); err == nil && !found[n] {
+					found[n] = true
+					numbers = append(numbers, n)
+				}
+			}
+		}
+	}
+	return
+}
+
+// PreviousRelease 找出版本小於 tag 的最新一版 release, final release 只會跟 final release 比較
+func PreviousRelease(releases []*Release, tag string) *Release {
+	current, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+	if err != nil {
+		return nil
+	}
+	var prev *Release
+	var prevVersion semver.Version
+	for _, r := range releases {
+		if r.Draft || (len(current.Pre) == 0 && r.Prerelease) {
+			continue
+		}
+		v, err := semver.Parse(strings.TrimPrefix(r.TagName, "v"))
+		if err != nil || !v.LT(current) {
+			continue
+		}
+		if prev == nil || v.GT(prevVersion) {
+			prev, prevVersion = r, v
+		}
+	}
+	return prev
+}
+
+// GenerateNotes 比較前一版 release 到 head 之間的 commits 及 pull requests, 產生 release notes
+func GenerateNotes(log *logrus.Logger, s SCM, tag, head string) (*Notes, error) {
+	releases, err := s.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+	prev := PreviousRelease(releases, tag)
+	if prev == nil {
+		log.Debugf("no previous release found for %s", tag)
+		return &Notes{Name: tag, Body: RenderNotes("", tag, nil, nil)}, nil
+	}
+	log.Debugf("collecting changes between %s and %s", prev.TagName, head)
+	commits, pulls, err := s.Changes(prev.TagName, head)
+	if err != nil {
+		return nil, err
+	}
+	return &Notes{Name: tag, Body: RenderNotes(prev.TagName, tag, commits, pulls)}, nil
+}
+
+// RenderNotes 將 commits 及 pull requests 依照 label 或 conventional commit type 分組, 轉換成 markdown
+func RenderNotes(prev, tag string, commits []*Commit, pulls []*PullRequest) string {
+	grouped := make(map[string][]string)
+	covered := make(map[string]bool)
+	for _, pr := range pulls {
+		for _, sha := range pr.Commits {
+			covered[sha] = true
+		}
+		if pr.MergeCommit != "" {
+			covered[pr.MergeCommit] = true
+		}
+		section := labelSection(pr.Labels)
+		cc := ParseConventionalCommit(pr.Title)
+		if section == "" {
+			section = commitSection(cc)
+		}
+		grouped[section] = append(grouped[section], fmt.Sprintf("- %s (#%d)%s", cc.Description, pr.Number, by(pr.Author)))
+	}
+	for _, c := range commits {
+		subject := strings.SplitN(strings.TrimSpace(c.Message), "\n", 2)[0]
+		if covered[c.SHA] || mergeCommit.MatchString(subject) {
+			continue
+		}
+		cc := ParseConventionalCommit(c.Message)
+		section := commitSection(cc)
+		grouped[section] = append(grouped[section], fmt.Sprintf("- %s (%s)%s", cc.Description, short(c.SHA), by(c.Author)))
+	}
+
+	var b strings.Builder
+	b.WriteString("## What's Changed\n")
+	if len(grouped) == 0 {
+		b.WriteString("\nNo changes.\n")
+	}
+	for _, section := range sections {
+		if lines := grouped[section]; len(lines) > 0 {
+			fmt.Fprintf(&b, "\n### %s\n\n%s\n", section, strings.Join(lines, "\n"))
+		}
+	}
+	if prev != "" {
+		fmt.Fprintf(&b, "\n**Full Changelog**: %s...%s\n", prev, tag)
+	}
+	return b.String()
+}
+
+func labelSection(labels []string) string {
+	for _, l := range labels {
+		if section, found := labelSections[strings.ToLower(l)]; found {
+			return section
+		}
+	}
+	return ""
+}
+
+func commitSection(cc *ConventionalCommit) string {
+	if cc.Breaking {
+		return sectionBreaking
+	}
+	if section, found := typeSections[cc.Type]; found {
+		return section
+	}
+	return sectionOthers
+}
+
+func by(author string) string {
+	if author == "" {
+		return ""
+	}
+	return " @" + author
+}
+
+func short(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,88 @@
+package scm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		message  string
+		typ      string
+		scope    string
+		desc     string
+		breaking bool
+	}{
+		{"feat: add notes", "feat", "", "add notes", false},
+		{"fix(tag): handle v prefix\n\nsome body", "fix", "tag", "handle v prefix", false},
+		{"refactor!: drop SetBaseURL", "refactor", "", "drop SetBaseURL", true},
+		{"feat: new api\n\nBREAKING CHANGE: removed old api", "feat", "", "new api", true},
+		{"Update README.md", "", "", "Update README.md", false},
+	}
+	for _, tt := range tests {
+		c := ParseConventionalCommit(tt.message)
+		if c.Type != tt.typ || c.Scope != tt.scope || c.Description != tt.desc || c.Breaking != tt.breaking {
+			t.Errorf("ParseConventionalCommit(%q) = %+v", tt.message, c)
+		}
+	}
+}
+
+func TestPreviousRelease(t *testing.T) {
+	releases := []*Release{
+		{TagName: "1.3.0", Draft: true},
+		{TagName: "1.2.4-0", Prerelease: true},
+		{TagName: "1.2.3"},
+		{TagName: "1.2.2"},
+	}
+	if r := PreviousRelease(releases, "1.3.0"); r == nil || r.TagName != "1.2.3" {
+		t.Errorf("previous release of 1.3.0 should be 1.2.3, but got %v", r)
+	}
+	if r := PreviousRelease(releases, "1.3.0-0"); r == nil || r.TagName != "1.2.4-0" {
+		t.Errorf("previous release of 1.3.0-0 should be 1.2.4-0, but got %v", r)
+	}
+	if r := PreviousRelease(releases, "1.0.0"); r != nil {
+		t.Errorf("previous release of 1.0.0 should be nil, but got %v", r)
+	}
+}
+
+func TestRenderNotes(t *testing.T) {
+	commits := []*Commit{
+		{SHA: "aaaaaaaaaa", Message: "feat: add notes", Author: "matt"},
+		{SHA: "bbbbbbbbbb", Message: "fix typo"},
+		{SHA: "cccccccccc", Message: "Merge pull request #7 from softleader/docs"},
+		{SHA: "dddddddddd", Message: "docs: update README"},
+	}
+	pulls := []*PullRequest{
+		{Number: 7, Title: "Update README", Labels: []string{"documentation"}, Author: "jack", Commits: []string{"dddddddddd"}},
+	}
+	body := RenderNotes("1.2.3", "1.2.4", commits, pulls)
+	for _, expected := range []string{
+		"### Features\n\n- add notes (aaaaaaa) @matt\n",
+		"### Documentation\n\n- Update README (#7) @jack\n",
+		"### Others\n\n- fix typo (bbbbbbb)\n",
+		"**Full Changelog**: 1.2.3...1.2.4",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("notes should contain %q, but got:\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, "docs: update README") || strings.Contains(body, "Merge pull request") {
+		t.Errorf("commits covered by pull requests and merge commits should be skipped, but got:\n%s", body)
+	}
+}
+
+func TestRenderNotes_Squash(t *testing.T) {
+	commits := []*Commit{
+		{SHA: "eeeeeeeeee", Message: "feat: support gitea (#12)\n\n* feat: add client\n* fix: lint", Author: "matt"},
+	}
+	pulls := []*PullRequest{
+		{Number: 12, Title: "feat: support gitea", Author: "matt", Commits: []string{"1111111111", "2222222222"}, MergeCommit: "eeeeeeeeee"},
+	}
+	body := RenderNotes("1.2.3", "1.2.4", commits, pulls)
+	if expected := "### Features\n\n- support gitea (#12) @matt\n"; !strings.Contains(body, expected) {
+		t.Errorf("notes should contain %q, but got:\n%s", expected, body)
+	}
+	if strings.Contains(body, "eeeeeee") {
+		t.Errorf("squash commit of a pull request should be skipped, but got:\n%s", body)
+	}
+}
@@ -0,0 +1,29 @@
+package scm
+
+import (
+	"github.com/blang/semver"
+	"strings"
+)
+
+// NextPrereleaseNumber 從已存在的 tags 中找出 tag 的版本在 stage 下的下一個 pre-release 編號
+// e.g. 已有 1.2.3-0.1 及 1.2.3-0.2 時, 1.2.3 在 stage 0 的下一個編號為 3, 不存在任何編號時從 1 開始
+func NextPrereleaseNumber(tags []string, tag, stage string) (uint64, error) {
+	sv, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+	if err != nil {
+		return 0, err
+	}
+	var max uint64
+	for _, t := range tags {
+		v, err := semver.Parse(strings.TrimPrefix(t, "v"))
+		if err != nil || v.Major != sv.Major || v.Minor != sv.Minor || v.Patch != sv.Patch {
+			continue
+		}
+		if len(v.Pre) != 2 || v.Pre[0].String() != stage || !v.Pre[1].IsNum {
+			continue
+		}
+		if v.Pre[1].VersionNum > max {
+			max = v.Pre[1].VersionNum
+		}
+	}
+	return max + 1, nil
+}
@@ -0,0 +1,29 @@
+package scm
+
+import (
+	"testing"
+)
+
+func TestNextPrereleaseNumber(t *testing.T) {
+	tags := []string{"1.2.3", "1.2.3-0", "1.2.3-0.1", "1.2.3-0.2", "1.2.3-alpha.5", "1.2.4-0.9", "v2.0.0-rc.1"}
+	tests := []struct {
+		tag      string
+		stage    string
+		expected uint64
+	}{
+		{"1.2.3", "0", 3},
+		{"1.2.3-0", "alpha", 6},
+		{"1.2.3", "beta", 1},
+		{"v2.0.0", "rc", 2},
+		{"1.3.0", "0", 1},
+	}
+	for _, tt := range tests {
+		actual, err := NextPrereleaseNumber(tags, tt.tag, tt.stage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tt.expected {
+			t.Errorf("next pre-release number of %s in stage %s should be %d, but got %d", tt.tag, tt.stage, tt.expected, actual)
+		}
+	}
+}
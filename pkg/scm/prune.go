@@ -0,0 +1,69 @@
+package scm
+
+import (
+	"fmt"
+	"github.com/blang/semver"
+	"strings"
+	"time"
+)
+
+// PrunePolicy 代表 pre-release 的保留規則, 兩個規則都設定時必須同時符合才會刪除, final release 及未標示為 pre-release 的 release 永遠不會被刪除
+type PrunePolicy struct {
+	KeepPerMinor int           // 每個 minor 版本保留最新的 N 個 pre-release, 0 代表不限制
+	OlderThan    time.Duration // 只刪除發佈超過此時間的 pre-release, 0 代表不限制
+}
+
+// IsValid 檢查是否至少設定了一個規則
+func (p *PrunePolicy) IsValid() error {
+	if p.KeepPerMinor < 0 || p.OlderThan < 0 {
+		return fmt.Errorf("prune policy must not be negative")
+	}
+	if p.KeepPerMinor == 0 && p.OlderThan == 0 {
+		return fmt.Errorf("requires at least one prune policy, e.g. keep the last N pre-releases per minor or prune pre-releases older than N days")
+	}
+	return nil
+}
+
+// PruneCandidate 代表一個要被刪除的 pre-release 及其原因
+type PruneCandidate struct {
+	*Release
+	Reason string
+}
+
+// FindPrunable 依照 policy 找出要被刪除的 pre-release, releases 必須已經依照版本由新到舊排序 (如 FindTagsByMatcher 的結果)
+func FindPrunable(releases []*Release, policy PrunePolicy, now time.Time) (candidates []*PruneCandidate) {
+	kept := make(map[string]int) // major.minor -> 已保留的數量
+	for _, r := range releases {
+		sv, err := semver.Parse(strings.TrimPrefix(r.TagName, "v"))
+		if err != nil || len(sv.Pre) == 0 { // 不是 pre-release 的一律不碰
+			continue
+		}
+		if r.Status != StatusTagOnly && (!r.Prerelease || r.Draft) { // release 沒有標示為 pre-release 或還是草稿的也不碰
+			continue
+		}
+		minor := fmt.Sprintf("%d.%d", sv.Major, sv.Minor)
+		var reasons []string
+		if policy.KeepPerMinor > 0 {
+			if kept[minor] < policy.KeepPerMinor {
+				kept[minor]++
+				continue
+			}
+			reasons = append(reasons, fmt.Sprintf("exceeds the last %d pre-release(s) of %s", policy.KeepPerMinor, minor))
+		}
+		if policy.OlderThan > 0 {
+			if r.PublishedAt.IsZero() { // tag only 的沒有發佈時間, 無法判斷就保留
+				continue
+			}
+			age := now.Sub(r.PublishedAt)
+			if age <= policy.OlderThan {
+				continue
+			}
+			reasons = append(reasons, fmt.Sprintf("published %d day(s) ago", int(age.Hours()/24)))
+		}
+		candidates = append(candidates, &PruneCandidate{
+			Release: r,
+			Reason:  strings.Join(reasons, " and "),
+		})
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package scm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindPrunable(t *testing.T) {
+	now := time.Now()
+	days := func(n int) time.Time {
+		return now.Add(-time.Duration(n) * 24 * time.Hour)
+	}
+	releases := []*Release{
+		{TagName: "1.3.0-0.3", Draft: true, Prerelease: true, Status: StatusReleaseOnly},
+		{TagName: "1.3.0-0.2", Prerelease: true, PublishedAt: days(1)},
+		{TagName: "1.3.0-0.1", Prerelease: true, PublishedAt: days(40)},
+		{TagName: "1.2.1", PublishedAt: days(50)},
+		{TagName: "1.2.1-0", Prerelease: true, PublishedAt: days(51)},
+		{TagName: "1.2.0-3", PublishedAt: days(70)}, // release 沒有標示為 pre-release
+		{TagName: "1.2.0-2", Prerelease: true, PublishedAt: days(60)},
+		{TagName: "1.2.0-1", Prerelease: true, PublishedAt: days(2)},
+		{TagName: "1.2.0-0", Status: StatusTagOnly},
+		{TagName: "1.0.0", PublishedAt: days(365)},
+	}
+	tests := []struct {
+		policy   PrunePolicy
+		expected string
+	}{
+		{PrunePolicy{KeepPerMinor: 1}, "1.3.0-0.1 1.2.0-2 1.2.0-1 1.2.0-0"},
+		{PrunePolicy{OlderThan: 30 * 24 * time.Hour}, "1.3.0-0.1 1.2.1-0 1.2.0-2"},
+		{PrunePolicy{KeepPerMinor: 1, OlderThan: 30 * 24 * time.Hour}, "1.3.0-0.1 1.2.0-2"},
+	}
+	for _, tt := range tests {
+		var tags []string
+		for _, c := range FindPrunable(releases, tt.policy, now) {
+			tags = append(tags, c.TagName)
+			if c.Reason == "" {
+				t.Errorf("%+v: reason of %s should not be empty", tt.policy, c.TagName)
+			}
+		}
+		if actual := strings.Join(tags, " "); actual != tt.expected {
+			t.Errorf("%+v: should prune %q, but got %q", tt.policy, tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Package restapi 是 GitLab 及 Gitea 等以 rest api 實作 scm.SCM 時共用的 client 設定及錯誤處理
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
+	"gopkg.in/resty.v1"
+	"net/http"
+	"time"
+)
+
+const (
+	maxRetries       = 3
+	retryWaitTime    = 5 * time.Second
+	retryMaxWaitTime = time.Minute
+)
+
+// NewClient 建立連到 hostURL 的 resty client, 觸發 rate limit 時會重試
+func NewClient(log *logrus.Logger, hostURL string) *resty.Client {
+	return resty.New().
+		SetHostURL(hostURL).
+		SetDisableWarn(true).
+		SetDebug(log.IsLevelEnabled(logrus.DebugLevel)).
+		SetRetryCount(maxRetries).
+		SetRetryWaitTime(retryWaitTime).
+		SetRetryMaxWaitTime(retryMaxWaitTime).
+		AddRetryCondition(tooManyRequests)
+}
+
+// tooManyRequests 在觸發 rate limit 時重試, 會以 exponential backoff 等待
+func tooManyRequests(resp *resty.Response) (bool, error) {
+	return resp != nil && resp.StatusCode() == http.StatusTooManyRequests, nil
+}
+
+// Error 代表 api 回傳了非 2xx 的 status code
+type Error struct {
+	Name       string // 回傳錯誤的服務, e.g. gitlab
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s responded %d: %s", e.Name, e.StatusCode, e.Message)
+}
+
+// NewError 將非 2xx 的 response 轉換成 error, 404 時回傳 scm.ErrNotFound
+// 錯誤訊息取自 body 中的 'message' (GitLab 可能是物件) 或 'error'
+func NewError(name string, resp *resty.Response) error {
+	if resp.StatusCode() == http.StatusNotFound {
+		return scm.ErrNotFound
+	}
+	var body struct {
+		Message interface{} `json:"message"`
+		Error   string      `json:"error"`
+	}
+	e := &Error{Name: name, StatusCode: resp.StatusCode(), Message: resp.Status()}
+	if err := json.Unmarshal(resp.Body(), &body); err == nil {
+		if body.Message != nil && body.Message != "" {
+			e.Message = fmt.Sprint(body.Message)
+		} else if body.Error != "" {
+			e.Message = body.Error
+		}
+	}
+	return e
+}
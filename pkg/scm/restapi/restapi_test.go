@@ -0,0 +1,54 @@
+package restapi
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/softleader/s2i/pkg/scm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/gitlab":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"message":{"tag_name":["is invalid"]}}`)
+		case "/gitea":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"message":"release already exists"}`)
+		case "/error":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":"insufficient_scope"}`)
+		}
+	}))
+	defer server.Close()
+	c := NewClient(logrus.New(), server.URL)
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/gitlab", "test responded 400: map[tag_name:[is invalid]]"},
+		{"/gitea", "test responded 409: release already exists"},
+		{"/error", "test responded 403: insufficient_scope"},
+	}
+	for _, tt := range tests {
+		resp, err := c.R().Get(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := NewError("test", resp); err.Error() != tt.expected {
+			t.Errorf("%s: expected %q, but got %q", tt.path, tt.expected, err)
+		}
+	}
+	resp, err := c.R().Get("/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewError("test", resp); err != scm.ErrNotFound {
+		t.Errorf("404 should be scm.ErrNotFound, but got %v", err)
+	}
+}
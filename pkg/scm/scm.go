@@ -0,0 +1,70 @@
+package scm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrNotFound 代表要查詢的 release 不存在
+	ErrNotFound = errors.New("not found")
+)
+
+// RefNotFoundError 代表要建立或移動 tag 的目標 (branch 或 commit SHA) 不存在
+type RefNotFoundError struct {
+	Ref string
+}
+
+func (e *RefNotFoundError) Error() string {
+	return fmt.Sprintf("ref %s not found", e.Ref)
+}
+
+// SCM 代表存放 source code 的服務 (e.g. GitHub, GitLab, Gitea), 可以管理該 repo 的 tag 及 release
+type SCM interface {
+	// CreateRelease 在 commitish (branch 或 commit SHA) 上建立 release, notes 為 nil 時不填寫 release notes
+	CreateRelease(commitish, tag string, notes *Notes) (*Release, error)
+	// CreatePrerelease 在 commitish (branch 或 commit SHA) 上建立 pre-release, force 時會先刪除已存在的同名 tag
+	CreatePrerelease(commitish, tag string, notes *Notes, force bool) (*Release, error)
+	// CreateDraftRelease 在 commitish (branch 或 commit SHA) 上建立 draft release, 不支援 draft 的 SCM 回傳錯誤
+	CreateDraftRelease(commitish, tag string, notes *Notes, prerelease bool) (*Release, error)
+	// DeleteReleaseAndTag 刪除 release 及其 tag, 只有其中之一不存在時不回傳錯誤, 兩者都不存在時回傳 ErrNotFound
+	DeleteReleaseAndTag(tag string, dryRun bool) error
+	// ListReleases 列出所有的 release
+	ListReleases() ([]*Release, error)
+	// LatestRelease 回傳最新一版非 pre-release 的 release, 不存在時回傳 ErrNotFound
+	LatestRelease() (*Release, error)
+	// GetRelease 回傳 tag 的 release, 不存在時回傳 ErrNotFound
+	GetRelease(tag string) (*Release, error)
+	// ListTags 列出所有的 tag 名稱
+	ListTags() ([]string, error)
+	// ResolveTag 回傳 tag 指向的 commit SHA, tag 不存在時回傳 ErrNotFound
+	ResolveTag(tag string) (string, error)
+	// TagMessage 回傳 annotated tag 的 message, lightweight tag 回傳空字串, tag 不存在時回傳 ErrNotFound
+	TagMessage(tag string) (string, error)
+	// CreateTag 在 commitish (branch 或 commit SHA) 上建立 tag, opts 為 nil 時建立 lightweight tag, commitish 不存在時回傳 *RefNotFoundError
+	CreateTag(commitish, tag string, opts *TagOptions) error
+	// MoveTag 將已存在的 tag 移到 commitish (branch 或 commit SHA) 上, tag 不存在時回傳 ErrNotFound, commitish 不存在時回傳 *RefNotFoundError
+	MoveTag(tag, commitish string, opts *TagOptions) error
+	// CommitExists 判斷 commit 是否已經 push 到 remote 上
+	CommitExists(sha string) (bool, error)
+	// Changes 回傳 base 到 head 之間的 commits 及已 merge 的 pull requests
+	Changes(base, head string) ([]*Commit, []*PullRequest, error)
+}
+
+// Release 代表 SCM 上的一個 release
+type Release struct {
+	TagName         string `json:"tag_name" yaml:"tag-name"`
+	TargetCommitish string `json:"target_commitish" yaml:"target-commitish"`
+	Name            string `json:"name" yaml:"name"`
+	Body            string `json:"body,omitempty" yaml:"body,omitempty"`
+	Draft           bool   `json:"draft" yaml:"draft"`
+	Prerelease      bool   `json:"prerelease" yaml:"prerelease"`
+
+	PublishedAt time.Time `json:"published_at" yaml:"published-at"`
+	HTMLURL     string    `json:"html_url" yaml:"html-url"`
+	Author      string    `json:"author" yaml:"author"`
+
+	// Status 在合併 tags 及 releases 時標示只存在其中一邊, 兩邊都存在時為空字串
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+}
@@ -0,0 +1,225 @@
+package scm
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSCM 是存放在記憶體中的 SCM, 方便測試
+type fakeSCM struct {
+	releases []*Release
+	tags     []string
+	deleted  []string
+	failing  map[string]bool // 刪除時會失敗的 tag
+	commits  []*Commit
+	pulls    []*PullRequest
+	mu       sync.Mutex
+}
+
+func (f *fakeSCM) CreateRelease(commitish, tag string, notes *Notes) (*Release, error) {
+	r := &Release{TagName: tag, Name: tag, TargetCommitish: commitish}
+	f.releases = append([]*Release{r}, f.releases...)
+	f.tags = append(f.tags, tag)
+	return r, nil
+}
+
+func (f *fakeSCM) CreatePrerelease(commitish, tag string, notes *Notes, force bool) (*Release, error) {
+	r, err := f.CreateRelease(commitish, tag, notes)
+	r.Prerelease = true
+	return r, err
+}
+
+func (f *fakeSCM) CreateDraftRelease(commitish, tag string, notes *Notes, prerelease bool) (*Release, error) {
+	r := &Release{TagName: tag, Name: tag, TargetCommitish: commitish, Draft: true, Prerelease: prerelease}
+	f.releases = append([]*Release{r}, f.releases...)
+	return r, nil
+}
+
+func (f *fakeSCM) DeleteReleaseAndTag(tag string, dryRun bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failing[tag] {
+		return errors.New("boom")
+	}
+	found := false
+	for _, t := range f.tags {
+		found = found || t == tag
+	}
+	if !found {
+		return ErrNotFound
+	}
+	if !dryRun {
+		f.deleted = append(f.deleted, tag)
+	}
+	return nil
+}
+
+func (f *fakeSCM) ListReleases() ([]*Release, error) {
+	return f.releases, nil
+}
+
+func (f *fakeSCM) LatestRelease() (*Release, error) {
+	for _, r := range f.releases {
+		if !r.Prerelease && !r.Draft {
+			return r, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (f *fakeSCM) GetRelease(tag string) (*Release, error) {
+	for _, r := range f.releases {
+		if r.TagName == tag {
+			return r, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (f *fakeSCM) ListTags() ([]string, error) {
+	return f.tags, nil
+}
+
+func (f *fakeSCM) ResolveTag(tag string) (string, error) {
+	for _, t := range f.tags {
+		if t == tag {
+			return "sha-of-" + tag, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (f *fakeSCM) TagMessage(tag string) (string, error) {
+	if _, err := f.ResolveTag(tag); err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+func (f *fakeSCM) CreateTag(commitish, tag string, opts *TagOptions) error {
+	f.tags = append(f.tags, tag)
+	return nil
+}
+
+func (f *fakeSCM) MoveTag(tag, commitish string, opts *TagOptions) error {
+	if _, err := f.ResolveTag(tag); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (f *fakeSCM) CommitExists(sha string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeSCM) Changes(base, head string) ([]*Commit, []*PullRequest, error) {
+	return f.commits, f.pulls, nil
+}
+
+func TestFindNextReleaseVersion(t *testing.T) {
+	s := &fakeSCM{}
+	s.CreateRelease("master", "v1.2.3", nil)
+	s.CreatePrerelease("master", "v1.2.4-0", nil, false)
+	next, err := FindNextReleaseVersion(logrus.StandardLogger(), s, BumpPatch, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "v1.2.4" {
+		t.Errorf("next version should be v1.2.4, but got %q", next)
+	}
+}
+
+func TestDeleteMatchesReleasesAndTags(t *testing.T) {
+	s := &fakeSCM{tags: []string{"1.0.0", "1.0.1-0", "1.1.0", "2.0.0-0"}}
+	matcher, err := NewSemVerMatcher([]string{"<1.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := DeleteMatchesReleasesAndTags(logrus.StandardLogger(), s, matcher, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(s.deleted)
+	if len(s.deleted) != 2 || s.deleted[0] != "1.0.0" || s.deleted[1] != "1.0.1-0" {
+		t.Errorf("should delete 1.0.0 and 1.0.1-0, but got %v", s.deleted)
+	}
+	if len(summary.Deleted) != 2 || summary.Err() != nil {
+		t.Errorf("summary should contain 2 deleted tags, but got %+v", summary)
+	}
+}
+
+func TestDeleteReleasesAndTags(t *testing.T) {
+	s := &fakeSCM{tags: []string{"1.0.0", "1.0.1", "1.0.2"}, failing: map[string]bool{"1.0.1": true}}
+	summary := DeleteReleasesAndTags(logrus.StandardLogger(), s, []string{"1.0.0", "1.0.1", "1.0.2", "2.0.0"}, 3, false)
+	if strings.Join(summary.Deleted, " ") != "1.0.0 1.0.2" {
+		t.Errorf("should delete 1.0.0 and 1.0.2, but got %v", summary.Deleted)
+	}
+	if strings.Join(summary.Skipped, " ") != "2.0.0" {
+		t.Errorf("should skip 2.0.0, but got %v", summary.Skipped)
+	}
+	if _, found := summary.Failed["1.0.1"]; !found || len(summary.Failed) != 1 || summary.Err() == nil {
+		t.Errorf("should fail to delete 1.0.1, but got %v", summary.Failed)
+	}
+}
+
+func TestFindNextReleaseVersion_Auto(t *testing.T) {
+	s := &fakeSCM{
+		commits: []*Commit{{SHA: "a", Message: "fix: typo"}, {SHA: "b", Message: "feat: add bump"}},
+	}
+	s.CreateRelease("master", "v1.2.3", nil)
+	next, err := FindNextReleaseVersion(logrus.StandardLogger(), s, BumpAuto, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "v1.3.0" {
+		t.Errorf("next version should be v1.3.0, but got %q", next)
+	}
+}
+
+func TestFindTags(t *testing.T) {
+	s := &fakeSCM{}
+	s.CreateRelease("master", "1.0.0", nil)
+	s.CreatePrerelease("master", "1.1.0-0", nil, false)
+	s.tags = append(s.tags, "1.0.1")
+	releases, err := FindTags(logrus.StandardLogger(), s, []string{"1.0.0", "2.0.0", "1.1.0-0", "1.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags []string
+	for _, r := range releases {
+		tags = append(tags, r.TagName+"/"+r.Status)
+	}
+	if expected := "1.1.0-0/ 1.0.1/tag only 1.0.0/"; strings.Join(tags, " ") != expected {
+		t.Errorf("should be %q, but got %q", expected, strings.Join(tags, " "))
+	}
+}
+
+func TestFindTagsByMatcher(t *testing.T) {
+	s := &fakeSCM{
+		tags: []string{"1.0.0", "v1.10.0", "1.2.0", "latest"},
+		releases: []*Release{
+			{TagName: "1.2.0"},
+			{TagName: "1.3.0", Draft: true},
+			{TagName: "1.0.0"},
+		},
+	}
+	matcher, err := NewRegexMatcher([]string{".+"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	releases, err := FindTagsByMatcher(logrus.StandardLogger(), s, matcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags []string
+	for _, r := range releases {
+		tags = append(tags, r.TagName+"/"+r.Status)
+	}
+	if expected := "v1.10.0/tag only 1.3.0/release only 1.2.0/ 1.0.0/ latest/tag only"; strings.Join(tags, " ") != expected {
+		t.Errorf("should be %q, but got %q", expected, strings.Join(tags, " "))
+	}
+}
@@ -0,0 +1,121 @@
+package scm
+
+import (
+	"fmt"
+	"github.com/blang/semver"
+	"strings"
+)
+
+const (
+	// StageAlpha 代表 alpha 階段
+	StageAlpha = "alpha"
+	// StageBeta 代表 beta 階段
+	StageBeta = "beta"
+	// StageRC 代表 release candidate 階段
+	StageRC = "rc"
+)
+
+// Stages 代表 pre-release 各開發階段對應到 tag 上的 identifier, 開發階段只能依照 alpha, beta, rc 的順序前進
+type Stages struct {
+	Alpha string `yaml:"alpha"`
+	Beta  string `yaml:"beta"`
+	RC    string `yaml:"rc"`
+}
+
+// DefaultStages 回傳預設的 identifier: 0 for alpha, 1 for beta, 2 for release candidate
+func DefaultStages() *Stages {
+	return &Stages{
+		Alpha: "0",
+		Beta:  "1",
+		RC:    "2",
+	}
+}
+
+// Identifier 回傳 stage 對應的 identifier, stage 不是 alpha, beta 或 rc 時直接當作 identifier 回傳
+func (s *Stages) Identifier(stage string) string {
+	switch strings.ToLower(stage) {
+	case StageAlpha:
+		return s.Alpha
+	case StageBeta:
+		return s.Beta
+	case StageRC:
+		return s.RC
+	}
+	return stage
+}
+
+// Name 回傳 identifier 對應的 stage 名稱, 不是任何 stage 的 identifier 時回傳空字串
+func (s *Stages) Name(identifier string) string {
+	switch identifier {
+	case s.Alpha:
+		return StageAlpha
+	case s.Beta:
+		return StageBeta
+	case s.RC:
+		return StageRC
+	}
+	return ""
+}
+
+// Of 回傳 tag 所屬的 stage 名稱, 不是 pre-release 或不屬於任何 stage 時回傳空字串
+func (s *Stages) Of(tag string) string {
+	sv, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+	if err != nil || len(sv.Pre) == 0 {
+		return ""
+	}
+	return s.Name(sv.Pre[0].String())
+}
+
+// CheckForward 檢查 tag 的版本在 tags 中是否已經進入比 stage 更後面的階段, e.g. 已有 1.2.3-rc 時不能再建立 1.2.3-beta
+// stage 不屬於 alpha, beta 或 rc 時不做檢查
+func (s *Stages) CheckForward(tags []string, tag, stage string) error {
+	current := s.rank(s.Name(s.Identifier(stage)))
+	if current < 0 {
+		return nil
+	}
+	sv, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+	if err != nil {
+		return err
+	}
+	for _, t := range tags {
+		v, err := semver.Parse(strings.TrimPrefix(t, "v"))
+		if err != nil || v.Major != sv.Major || v.Minor != sv.Minor || v.Patch != sv.Patch || len(v.Pre) == 0 {
+			continue
+		}
+		if name := s.Name(v.Pre[0].String()); s.rank(name) > current {
+			return fmt.Errorf("%s is already in stage %s, stage can only move forward (%s -> %s -> %s)", t, name, StageAlpha, StageBeta, StageRC)
+		}
+	}
+	return nil
+}
+
+func (s *Stages) rank(name string) int {
+	switch name {
+	case StageAlpha:
+		return 0
+	case StageBeta:
+		return 1
+	case StageRC:
+		return 2
+	}
+	return -1
+}
+
+// StageMatcher 判斷 tag 是否屬於某個 stage
+type StageMatcher struct {
+	identifier string
+}
+
+// NewStageMatcher 建立 StageMatcher 物件, stage 可以是 alpha, beta, rc 或任意 identifier
+func NewStageMatcher(stages *Stages, stage string) *StageMatcher {
+	return &StageMatcher{identifier: stages.Identifier(stage)}
+}
+
+// Matches 判斷傳入 tag 的第一個 pre-release identifier 是否為該 stage
+func (m *StageMatcher) Matches(s string) bool {
+	sv, err := semver.Parse(strings.TrimPrefix(s, "v"))
+	if err != nil || len(sv.Pre) == 0 {
+		return false
+	}
+	return sv.Pre[0].String() == m.identifier
+}
@@ -0,0 +1,41 @@
+package scm
+
+import (
+	"testing"
+)
+
+func TestStages_CheckForward(t *testing.T) {
+	s := DefaultStages()
+	tags := []string{"1.2.3-0", "1.2.3-0.1", "1.2.3-2", "1.2.4-0", "1.2.2"}
+	if err := s.CheckForward(tags, "1.2.3", StageRC); err != nil {
+		t.Errorf("rc of 1.2.3 should be allowed, but got %s", err)
+	}
+	if err := s.CheckForward(tags, "1.2.3", StageBeta); err == nil {
+		t.Error("beta of 1.2.3 should not be allowed after rc")
+	}
+	if err := s.CheckForward(tags, "1.2.3", "0"); err == nil {
+		t.Error("identifier 0 (alpha) of 1.2.3 should not be allowed after rc")
+	}
+	if err := s.CheckForward(tags, "1.2.4", StageBeta); err != nil {
+		t.Errorf("beta of 1.2.4 should be allowed, but got %s", err)
+	}
+	if err := s.CheckForward(tags, "1.2.3", "do.not.use"); err != nil {
+		t.Errorf("unknown stage should not be checked, but got %s", err)
+	}
+}
+
+func TestStageMatcher(t *testing.T) {
+	s := &Stages{Alpha: "alpha", Beta: "beta", RC: "rc"}
+	m := NewStageMatcher(s, "RC")
+	for tag, expected := range map[string]bool{
+		"1.2.3-rc":      true,
+		"v1.2.3-rc.2":   true,
+		"1.2.3-beta.1":  false,
+		"1.2.3":         false,
+		"not-a-version": false,
+	} {
+		if actual := m.Matches(tag); actual != expected {
+			t.Errorf("%s matches stage rc should be %v, but got %v", tag, expected, actual)
+		}
+	}
+}
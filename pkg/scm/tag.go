@@ -0,0 +1,64 @@
+package scm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tagger 代表 annotated tag 的建立人員
+type Tagger struct {
+	Name  string
+	Email string
+	Date  time.Time
+}
+
+// TagOptions 是建立 tag 的選項, 為 nil 或沒有 Message 時建立 lightweight tag
+type TagOptions struct {
+	// Message 不為空時建立 annotated tag
+	Message string
+	// Tagger 為 annotated tag 的建立人員, 簽署時為必要
+	Tagger *Tagger
+	// Sign 不為 nil 時會簽署 annotated tag, 傳入 tag object 的內容, 回傳 armored 簽章
+	Sign func(payload []byte) ([]byte, error)
+}
+
+// Annotated 判斷是否要建立 annotated tag
+func (o *TagOptions) Annotated() bool {
+	return o != nil && o.Message != ""
+}
+
+// Signed 判斷是否要簽署 tag
+func (o *TagOptions) Signed() bool {
+	return o.Annotated() && o.Sign != nil
+}
+
+// SignedMessage 回傳附上簽章後的 tag message, 簽章的內容與 git 在本機簽署 tag 時相同, 因此建立的 tag 可以透過 'git tag -v' 驗證
+func (o *TagOptions) SignedMessage(sha, tag string) (string, error) {
+	message := TagMessage(o.Message)
+	if !o.Signed() {
+		return message, nil
+	}
+	if o.Tagger == nil {
+		return "", fmt.Errorf("tagger is required to sign tag %s", tag)
+	}
+	sig, err := o.Sign(TagPayload(sha, tag, o.Tagger, message))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign tag %s: %s", tag, err)
+	}
+	return message + string(sig), nil
+}
+
+// TagMessage 與 git 相同, 確保 message 以換行結尾
+func TagMessage(message string) string {
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	return message
+}
+
+// TagPayload 回傳 annotated tag 在 git 中的 object 內容 (不含簽章), 也就是要被簽署的內容
+func TagPayload(sha, tag string, tagger *Tagger, message string) []byte {
+	return []byte(fmt.Sprintf("object %s\ntype commit\ntag %s\ntagger %s <%s> %d %s\n\n%s",
+		sha, tag, tagger.Name, tagger.Email, tagger.Date.Unix(), tagger.Date.Format("-0700"), TagMessage(message)))
+}
@@ -0,0 +1,201 @@
+package scm
+
+import (
+	"fmt"
+	"github.com/blang/semver"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TagMatcherStrategy 用來方便判斷是哪個 matcher, 判斷先後順序依序為: Regex, SemVer, Glob, 都沒有時為完全比對
+type TagMatcherStrategy struct {
+	Regex  bool `yaml:"regex"`
+	SemVer bool `yaml:"semver"`
+	Glob   bool `yaml:"glob"`
+}
+
+// IsExact 判斷是否為完全比對
+func (s *TagMatcherStrategy) IsExact() bool {
+	return !s.Regex && !s.SemVer && !s.Glob
+}
+
+// NewMatcher 依照 strategy 建立對應的 TagMatcher
+func (s *TagMatcherStrategy) NewMatcher(exprs []string) (TagMatcher, error) {
+	switch {
+	case s.Regex:
+		return NewRegexMatcher(exprs)
+	case s.SemVer:
+		return NewSemVerMatcher(exprs)
+	case s.Glob:
+		return NewGlobMatcher(exprs)
+	}
+	return NewExactMatcher(exprs), nil
+}
+
+// TagMatcher 判斷是否有 match tags
+type TagMatcher interface {
+	// Matches 傳入的 string 是否匹配
+	Matches(s string) bool
+}
+
+// NewRegexMatcher 建立 RegexMatcher 物件
+func NewRegexMatcher(exprs []string) (*RegexMatcher, error) {
+	m := &RegexMatcher{}
+	for _, expr := range exprs {
+		rr, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("requires a valid regexp expression: %s", err)
+		}
+		m.regexps = append(m.regexps, rr)
+	}
+	return m, nil
+}
+
+// RegexMatcher regex 判斷
+type RegexMatcher struct {
+	regexps []*regexp.Regexp
+}
+
+// Matches 判斷傳入 tag 是否匹配
+func (m *RegexMatcher) Matches(s string) bool {
+	for _, r := range m.regexps {
+		if r.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSemVerMatcher 建立 SemVerMatcher 物件
+func NewSemVerMatcher(ranges []string) (*SemVerMatcher, error) {
+	m := &SemVerMatcher{}
+	for _, r := range ranges {
+		rr, err := semver.ParseRange(r)
+		if err != nil {
+			return nil, fmt.Errorf("requires a valid semver2 ranges: %s", err)
+		}
+		if m.r == nil {
+			m.r = rr
+		} else {
+			m.r = m.r.OR(rr)
+		}
+	}
+	return m, nil
+}
+
+// SemVerMatcher 以 Semantic Versioning 2.0.0 判斷
+type SemVerMatcher struct {
+	r semver.Range
+}
+
+// Matches 判斷傳入 tag 是否匹配
+func (m *SemVerMatcher) Matches(s string) bool {
+	v, err := semver.Parse(strings.TrimPrefix(s, "v"))
+	if err != nil {
+		return false
+	}
+	return m.r(v)
+}
+
+// NewGlobMatcher 建立 GlobMatcher 物件, pattern 語法同 path.Match, e.g. 1.2.*
+func NewGlobMatcher(patterns []string) (*GlobMatcher, error) {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("requires a valid glob pattern: %s", err)
+		}
+	}
+	return &GlobMatcher{patterns: patterns}, nil
+}
+
+// GlobMatcher 以 glob pattern 判斷
+type GlobMatcher struct {
+	patterns []string
+}
+
+// Matches 判斷傳入 tag 是否匹配
+func (m *GlobMatcher) Matches(s string) bool {
+	for _, p := range m.patterns {
+		if matched, _ := path.Match(p, s); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// NotMatcher 反轉 matcher 的結果, 用來排除符合的 tag
+type NotMatcher struct {
+	TagMatcher
+}
+
+// Matches 判斷傳入 tag 是否不匹配
+func (m NotMatcher) Matches(s string) bool {
+	return !m.TagMatcher.Matches(s)
+}
+
+// NewAuthorMatcher 建立 AuthorMatcher 物件, 以 releases 的發佈人員判斷, 沒有 release 的 tag 不會匹配
+func NewAuthorMatcher(releases []*Release, authors []string) ReleaseMatcher {
+	set := make(map[string]bool)
+	for _, a := range authors {
+		set[strings.ToLower(a)] = true
+	}
+	return newReleaseMatcher(releases, func(r *Release) bool {
+		return set[strings.ToLower(r.Author)]
+	})
+}
+
+// NewAgeMatcher 建立以 release 發佈時間判斷的 matcher, 發佈超過 olderThan 的才會匹配, 沒有 release 的 tag 不會匹配
+func NewAgeMatcher(releases []*Release, olderThan time.Duration, now time.Time) ReleaseMatcher {
+	return newReleaseMatcher(releases, func(r *Release) bool {
+		return !r.PublishedAt.IsZero() && now.Sub(r.PublishedAt) > olderThan
+	})
+}
+
+func newReleaseMatcher(releases []*Release, fn func(r *Release) bool) ReleaseMatcher {
+	m := make(ReleaseMatcher)
+	for _, r := range releases {
+		if fn(r) {
+			m[r.TagName] = true
+		}
+	}
+	return m
+}
+
+// ReleaseMatcher 以 release 的資訊 (如發佈人員, 發佈時間) 判斷, 記錄了所有匹配的 tag 名稱
+type ReleaseMatcher map[string]bool
+
+// Matches 判斷傳入 tag 的 release 是否匹配
+func (m ReleaseMatcher) Matches(s string) bool {
+	return m[s]
+}
+
+// AllMatcher 必須所有 matcher 都匹配才算匹配
+type AllMatcher []TagMatcher
+
+// Matches 判斷傳入 tag 是否匹配所有的 matcher
+func (m AllMatcher) Matches(s string) bool {
+	for _, matcher := range m {
+		if !matcher.Matches(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewExactMatcher 建立 ExactMatcher 物件
+func NewExactMatcher(tags []string) ExactMatcher {
+	m := make(ExactMatcher)
+	for _, tag := range tags {
+		m[tag] = true
+	}
+	return m
+}
+
+// ExactMatcher 完全比對 tag 名稱
+type ExactMatcher map[string]bool
+
+// Matches 判斷傳入 tag 是否為其中之一
+func (m ExactMatcher) Matches(s string) bool {
+	return m[s]
+}
@@ -0,0 +1,65 @@
+package scm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComposedMatchers(t *testing.T) {
+	now := time.Now()
+	releases := []*Release{
+		{TagName: "1.2.0-0", Author: "matt", PublishedAt: now.Add(-48 * time.Hour)},
+		{TagName: "1.2.1-0", Author: "jack", PublishedAt: now.Add(-48 * time.Hour)},
+		{TagName: "1.3.0-0", Author: "Jack", PublishedAt: now},
+		{TagName: "2.1.0-0", Author: "jack", PublishedAt: now.Add(-48 * time.Hour)},
+		{TagName: "1.2.0", Author: "jack", PublishedAt: now.Add(-48 * time.Hour)},
+	}
+	under2, err := NewSemVerMatcher([]string{"<2.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alpha, err := NewRegexMatcher([]string{`-0$`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 刪除 2.0 以下的 alpha, 但排除 matt 發佈的及一天內發佈的
+	m := AllMatcher{
+		under2,
+		alpha,
+		NotMatcher{NewAuthorMatcher(releases, []string{"MATT"})},
+		NewAgeMatcher(releases, 24*time.Hour, now),
+	}
+	for tag, expected := range map[string]bool{
+		"1.2.0-0": false, // matt
+		"1.2.1-0": true,
+		"1.3.0-0": false, // too new
+		"2.1.0-0": false, // >= 2.0.0
+		"1.2.0":   false, // not alpha
+		"1.1.0-0": false, // tag only, no release
+	} {
+		if actual := m.Matches(tag); actual != expected {
+			t.Errorf("%s should match %v, but got %v", tag, expected, actual)
+		}
+	}
+}
+
+func TestGlobMatcher(t *testing.T) {
+	m, err := NewGlobMatcher([]string{"1.2.*", "v2.?.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tag, expected := range map[string]bool{
+		"1.2.3":   true,
+		"1.2.3-0": true,
+		"1.3.0":   false,
+		"v2.1.0":  true,
+		"v2.10.0": false,
+	} {
+		if actual := m.Matches(tag); actual != expected {
+			t.Errorf("%s should match %v, but got %v", tag, expected, actual)
+		}
+	}
+	if _, err := NewGlobMatcher([]string{"[1.2"}); err == nil {
+		t.Error("should fail with bad pattern")
+	}
+}
@@ -0,0 +1,38 @@
+package scm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignedMessage(t *testing.T) {
+	tagger := &Tagger{Name: "Matt", Email: "matt@example.com", Date: time.Unix(1560000000, 0).In(time.FixedZone("", 8*60*60))}
+	var signed string
+	opts := &TagOptions{
+		Message: "release 1.0.0",
+		Tagger:  tagger,
+		Sign: func(payload []byte) ([]byte, error) {
+			signed = string(payload)
+			return []byte("-----BEGIN PGP SIGNATURE-----\n-----END PGP SIGNATURE-----\n"), nil
+		},
+	}
+	message, err := opts.SignedMessage("abc123", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "object abc123\ntype commit\ntag 1.0.0\ntagger Matt <matt@example.com> 1560000000 +0800\n\nrelease 1.0.0\n"
+	if signed != expected {
+		t.Errorf("expected payload:\n%s\nbut got:\n%s", expected, signed)
+	}
+	if expected := "release 1.0.0\n-----BEGIN PGP SIGNATURE-----\n-----END PGP SIGNATURE-----\n"; message != expected {
+		t.Errorf("expected message %q, but got %q", expected, message)
+	}
+
+	opts.Tagger = nil
+	if _, err := opts.SignedMessage("abc123", "1.0.0"); err == nil {
+		t.Error("should require tagger to sign")
+	}
+	if (&TagOptions{}).Annotated() {
+		t.Error("should be lightweight without message")
+	}
+}
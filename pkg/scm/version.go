@@ -0,0 +1,150 @@
+package scm
+
+import (
+	"fmt"
+	"github.com/blang/semver"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// Bump 代表下一版要增加的版號
+type Bump string
+
+const (
+	// BumpAuto 依照 latest release 之後的 conventional commits 決定要增加的版號
+	BumpAuto Bump = "auto"
+	// BumpMajor 增加 major 版號, e.g. 1.2.3 -> 2.0.0
+	BumpMajor Bump = "major"
+	// BumpMinor 增加 minor 版號, e.g. 1.2.3 -> 1.3.0
+	BumpMinor Bump = "minor"
+	// BumpPatch 增加 patch 版號, e.g. 1.2.3 -> 1.2.4
+	BumpPatch Bump = "patch"
+)
+
+// ParseBump 解析 auto, major, minor 或 patch
+func ParseBump(s string) (Bump, error) {
+	switch b := Bump(strings.ToLower(s)); b {
+	case BumpAuto, BumpMajor, BumpMinor, BumpPatch:
+		return b, nil
+	}
+	return "", fmt.Errorf("unsupported bump %q, must be one of: %s, %s, %s, %s", s, BumpAuto, BumpMajor, BumpMinor, BumpPatch)
+}
+
+// FindNextReleaseVersion 找下一版 revision, 也就是 latest release 增加 bump 版號
+// bump 為 BumpAuto 時會比較 latest release 到 head 之間的 commits 及 pull requests 來決定
+func FindNextReleaseVersion(log *logrus.Logger, s SCM, bump Bump, head string) (string, error) {
+	log.Debugf("fetching latest release")
+	rr, err := s.LatestRelease()
+	if err != nil {
+		return "", err
+	}
+	tag := rr.TagName
+	log.Debugf("found %s drafted by %s published at %s", tag, rr.Author, rr.PublishedAt)
+	if bump == BumpAuto {
+		commits, pulls, err := s.Changes(tag, head)
+		if err != nil {
+			return "", err
+		}
+		bump = DetectBump(commits, pulls)
+		log.Debugf("detected %s bump from %d commit(s) and %d pull request(s) since %s", bump, len(commits), len(pulls), tag)
+	}
+	return NextVersion(tag, bump)
+}
+
+// DetectBump 依照 conventional commits 決定要增加的版號:
+// 有 breaking change 時為 major, 有 feat 時為 minor, 其餘為 patch
+func DetectBump(commits []*Commit, pulls []*PullRequest) Bump {
+	var messages []string
+	for _, c := range commits {
+		messages = append(messages, c.Message)
+	}
+	for _, pr := range pulls {
+		messages = append(messages, pr.Title)
+	}
+	bump := BumpPatch
+	for _, m := range messages {
+		cc := ParseConventionalCommit(m)
+		if cc.Breaking {
+			return BumpMajor
+		}
+		if cc.Type == "feat" || cc.Type == "feature" {
+			bump = BumpMinor
+		}
+	}
+	return bump
+}
+
+// CheckVersion 檢查 tag 是否為有效的 semver2 版號, 允許 'v' prefix
+func CheckVersion(tag string) error {
+	// GitHub 建議我們用 v 開頭, 但 v 開頭不符合 semver, 所以檢查時固定拿掉
+	if _, err := semver.Parse(strings.TrimPrefix(tag, "v")); err != nil {
+		return fmt.Errorf("requires valid semver2 tag: %s", err)
+	}
+	return nil
+}
+
+// NextVersion 將 tag 增加 bump 版號, 並保留 tag 的 'v' prefix
+func NextVersion(tag string, bump Bump) (string, error) {
+	sv, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+	if err != nil {
+		return "", err
+	}
+	switch bump {
+	case BumpMajor:
+		sv.Major++
+		sv.Minor = 0
+		sv.Patch = 0
+	case BumpMinor:
+		sv.Minor++
+		sv.Patch = 0
+	case BumpPatch:
+		sv.Patch++
+	default:
+		return "", fmt.Errorf("unsupported bump %q", bump)
+	}
+	sv.Pre = nil
+	sv.Build = nil
+	next := sv.String()
+	if strings.HasPrefix(tag, "v") {
+		next = "v" + next
+	}
+	return next, nil
+}
+
+// FinalVersion 回傳 pre-release tag 對應的正式版號, 並保留 tag 的 'v' prefix, e.g. v1.2.3-0.1 為 v1.2.3
+func FinalVersion(tag string) (string, error) {
+	sv, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+	if err != nil {
+		return "", fmt.Errorf("requires valid semver2 tag: %s", err)
+	}
+	if len(sv.Pre) == 0 {
+		return "", fmt.Errorf("%s is not a pre-release", tag)
+	}
+	sv.Pre, sv.Build = nil, nil
+	if strings.HasPrefix(tag, "v") {
+		return "v" + sv.String(), nil
+	}
+	return sv.String(), nil
+}
+
+// IsPrerelease 判斷 tag 是否包含 semver 的 pre-release 版號, e.g. 1.2.3-0
+func IsPrerelease(tag string) bool {
+	sv, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+	if err != nil {
+		return false
+	}
+	return len(sv.Pre) > 0
+}
+
+// CompareVersions 比較兩個 tag 的 semver 版號, a 較舊時回傳 -1, 相同時回傳 0, a 較新時回傳 1, 允許 'v' prefix
+func CompareVersions(a, b string) (int, error) {
+	va, err := semver.Parse(strings.TrimPrefix(a, "v"))
+	if err != nil {
+		return 0, fmt.Errorf("requires valid semver2 tag: %s", err)
+	}
+	vb, err := semver.Parse(strings.TrimPrefix(b, "v"))
+	if err != nil {
+		return 0, fmt.Errorf("requires valid semver2 tag: %s", err)
+	}
+	return va.Compare(vb), nil
+}
@@ -0,0 +1,100 @@
+package scm
+
+import (
+	"testing"
+)
+
+func TestDetectBump(t *testing.T) {
+	tests := []struct {
+		messages []string
+		titles   []string
+		expected Bump
+	}{
+		{[]string{"fix: typo", "chore: update deps"}, nil, BumpPatch},
+		{[]string{"fix: typo", "feat(tag): add list"}, nil, BumpMinor},
+		{[]string{"feat: add list", "refactor!: drop api"}, nil, BumpMajor},
+		{[]string{"Merge pull request #1 from softleader/x"}, []string{"feat: add list"}, BumpMinor},
+		{[]string{"fix: api\n\nBREAKING CHANGE: renamed flag"}, nil, BumpMajor},
+	}
+	for _, tt := range tests {
+		var commits []*Commit
+		for _, m := range tt.messages {
+			commits = append(commits, &Commit{Message: m})
+		}
+		var pulls []*PullRequest
+		for _, title := range tt.titles {
+			pulls = append(pulls, &PullRequest{Title: title})
+		}
+		if actual := DetectBump(commits, pulls); actual != tt.expected {
+			t.Errorf("DetectBump(%v, %v) should be %s, but got %s", tt.messages, tt.titles, tt.expected, actual)
+		}
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		tag      string
+		bump     Bump
+		expected string
+	}{
+		{"1.2.3", BumpPatch, "1.2.4"},
+		{"v1.2.3", BumpMinor, "v1.3.0"},
+		{"1.2.3-0", BumpMajor, "2.0.0"},
+	}
+	for _, tt := range tests {
+		actual, err := NextVersion(tt.tag, tt.bump)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tt.expected {
+			t.Errorf("NextVersion(%q, %s) should be %q, but got %q", tt.tag, tt.bump, tt.expected, actual)
+		}
+	}
+}
+
+func TestFinalVersion(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"1.2.3-0", "1.2.3"},
+		{"v1.2.3-2.1", "v1.2.3"},
+		{"1.2.3-rc+build.5", "1.2.3"},
+	}
+	for _, tt := range tests {
+		actual, err := FinalVersion(tt.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tt.expected {
+			t.Errorf("FinalVersion(%q) should be %q, but got %q", tt.tag, tt.expected, actual)
+		}
+	}
+	if _, err := FinalVersion("1.2.3"); err == nil {
+		t.Error("should fail with final release")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "v1.2.3", 0},
+		{"1.2.3-0", "1.2.3", -1},
+		{"1.2.4-0", "1.2.3", 1},
+		{"v1.10.0", "1.9.9", 1},
+	}
+	for _, tt := range tests {
+		actual, err := CompareVersions(tt.a, tt.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tt.expected {
+			t.Errorf("CompareVersions(%q, %q) should be %d, but got %d", tt.a, tt.b, tt.expected, actual)
+		}
+	}
+	if _, err := CompareVersions("latest", "1.2.3"); err == nil {
+		t.Error("should fail with invalid version")
+	}
+}